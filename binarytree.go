@@ -22,24 +22,40 @@
 // (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
 // THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 
+// Package binarytree provides a simple, generic implementation of
+// binary trees.
+//
+// Depths, heights and levels are measured in edges throughout the
+// package. The node a query starts from is at depth (level) 0, and a
+// tree consisting of a single node has a height of 0.
 package binarytree
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
-
-	deque "gopkg.in/dnaeon/go-deque.v1"
+	"sync/atomic"
 )
 
 // WalkFunc is the type of the function which will be invoked while
 // visiting a node from the binary tree.
 type WalkFunc[T any] func(node *Node[T]) error
 
+// WalkDepthFunc is the type of the function which will be invoked
+// while visiting a node from the binary tree, along with the depth of
+// the node relative to the node the walk started from.
+type WalkDepthFunc[T any] func(node *Node[T], depth int) error
+
 // SkipNodeFunc is a function which returns true, if the currently
-// being visited node should be skipped.
+// being visited node should be skipped. See AddSkipNodeFunc for the
+// nodes whose handlers are applied during a walk.
 type SkipNodeFunc[T any] func(node *Node[T]) bool
 
 // FindFunc is the type of the function predicate which will be
@@ -48,18 +64,66 @@ type SkipNodeFunc[T any] func(node *Node[T]) bool
 // false otherwise.
 type FindFunc[T any] func(node *Node[T]) bool
 
+// TraversalOrder specifies the order in which the nodes of a tree
+// are visited.
+type TraversalOrder int
+
+const (
+	// InOrder visits the nodes Left-Node-Right (LNR)
+	InOrder TraversalOrder = iota
+	// PreOrder visits the nodes Node-Left-Right (NLR)
+	PreOrder
+	// PostOrder visits the nodes Left-Right-Node (LRN)
+	PostOrder
+	// LevelOrder visits the nodes level by level (Breadth-first)
+	LevelOrder
+)
+
+// ErrStopWalk is used as a return value from a WalkFunc to indicate
+// that walking of the tree should be stopped. It is not returned as an
+// error by any of the walking methods.
+var ErrStopWalk = errors.New("stop walk")
+
+// stopWalk translates ErrStopWalk returned by a walking function to a
+// successfully completed walk.
+func stopWalk(err error) error {
+	if errors.Is(err, ErrStopWalk) {
+		return nil
+	}
+
+	return err
+}
+
+// ErrInvalidTraversalOrder is returned when an unknown traversal
+// order is requested.
+var ErrInvalidTraversalOrder = errors.New("invalid traversal order")
+
 // ComparatorFunc is a function which compares two values of type T.
 // The comparator function should return:
 //
 // - A negative integer when A is less than B
 // - A positive integer when A is greater than B
 // - Zero when A equals B
+// - Incomparable when A and B cannot be compared
 //
 // The comparator function is used by IsBinarySearchTree method for
 // validating whether a given binary tree is a Binary Search Tree
 // (BST).
 type ComparatorFunc[T any] func(a, b T) int
 
+// Incomparable is a special value, which a ComparatorFunc returns in
+// order to signal that two values cannot be compared, e.g. when the
+// values are only partially ordered.
+const Incomparable = math.MinInt
+
+// Number is a constraint, which permits any integer or floating-point
+// type.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
 // IntComparator is a comparator function for comparing integer node
 // values.
 func IntComparator(a, b int) int {
@@ -87,6 +151,13 @@ type Node[T any] struct {
 	// Right child of the node
 	Right *Node[T]
 
+	// parent is the parent of the node, or nil for the root node
+	parent *Node[T]
+
+	// id is the stable identifier of the node, which is assigned
+	// upon creation of the node.
+	id uint64
+
 	// A list of function handlers, which specify whether a node
 	// should be skipped or not during tree walking.
 	skipNodeFuncs []SkipNodeFunc[T]
@@ -95,12 +166,36 @@ type Node[T any] struct {
 	// with the node, which will be used when generating the Dot
 	// representation of the tree.
 	dotAttributes map[string]string
+
+	// meta holds arbitrary user-defined metadata associated with
+	// the node.
+	meta any
+
+	// subtreeMin and subtreeMax are the cached nodes holding the
+	// minimum and maximum values of the sub-tree rooted at the
+	// node. The cache is empty until bounds are computed.
+	subtreeMin *Node[T]
+	subtreeMax *Node[T]
+
+	// height is the cached height of the sub-tree rooted at the
+	// node, which is valid only if heightCached is true.
+	height       int
+	heightCached bool
+
+	// size is the cached size of the sub-tree rooted at the node,
+	// which is valid only if sizeCached is true.
+	size       int
+	sizeCached bool
 }
 
+// lastNodeId is the most recently assigned node id.
+var lastNodeId atomic.Uint64
+
 // NewNode creates a new node
 func NewNode[T any](value T) *Node[T] {
 	node := &Node[T]{
 		Value:         value,
+		id:            lastNodeId.Add(1),
 		Left:          nil,
 		Right:         nil,
 		skipNodeFuncs: make([]SkipNodeFunc[T], 0),
@@ -110,40 +205,179 @@ func NewNode[T any](value T) *Node[T] {
 	return node
 }
 
-// InsertLeft inserts a new node to the left
-func (n *Node[T]) InsertLeft(value T) *Node[T] {
-	left := NewNode(value)
-	n.Left = left
+// ID returns the stable identifier of the node. Unless set
+// explicitly via SetID, the id is assigned from a monotonic counter
+// when the node is created.
+func (n *Node[T]) ID() uint64 {
+	return n.id
+}
+
+// SetID sets a user-supplied identifier for the node.
+func (n *Node[T]) SetID(id uint64) {
+	n.id = id
+}
+
+// ValueOr returns the value of the node, or def if the node is nil.
+func (n *Node[T]) ValueOr(def T) T {
+	if n == nil {
+		return def
+	}
+
+	return n.Value
+}
+
+// SwapValues exchanges the values of the node and the other node. The
+// structure of the trees, the children and the Dot attributes of both
+// nodes are left untouched. Since the cached bounds no longer hold
+// after the swap, they are invalidated for both nodes and their
+// ancestors, and can be repaired via RecomputeBounds.
+func (n *Node[T]) SwapValues(other *Node[T]) {
+	n.Value, other.Value = other.Value, n.Value
+	n.invalidateBounds()
+	other.invalidateBounds()
+}
+
+// invalidateBounds clears the cached bounds of the node and its
+// ancestors. Since the bounds of the ancestors are invalidated along
+// with the node, the walk stops at the first node without cached
+// bounds.
+func (n *Node[T]) invalidateBounds() {
+	for node := n; node != nil && node.subtreeMin != nil; node = node.parent {
+		node.subtreeMin, node.subtreeMax = nil, nil
+	}
+}
+
+// Parent returns the parent of the node, or nil if the node is the
+// root of the tree.
+func (n *Node[T]) Parent() *Node[T] {
+	return n.parent
+}
+
+// Remove detaches the node and its sub-tree from the parent node.
+// The detached sub-tree remains usable as an independent tree rooted
+// at the node. Remove returns false, if the node is a root node, or
+// if its parent link is stale, i.e. the parent no longer refers to
+// the node as its child. In the latter case the stale parent link is
+// cleared.
+func (n *Node[T]) Remove() bool {
+	parent := n.parent
+	if parent == nil {
+		return false
+	}
+
+	n.parent = nil
+	switch n {
+	case parent.Left:
+		parent.Left = nil
+	case parent.Right:
+		parent.Right = nil
+	default:
+		return false
+	}
+	parent.updateCaches()
+	parent.invalidateBounds()
 
-	return left
+	return true
+}
+
+// SetMeta associates arbitrary metadata with the node, e.g. scratch
+// data used by algorithms. Unlike the Dot attributes, the metadata is
+// not used when rendering the tree, and is not serialized unless
+// explicitly requested.
+func (n *Node[T]) SetMeta(meta any) {
+	n.meta = meta
+}
+
+// Meta returns the metadata associated with the node, or nil if no
+// metadata has been set.
+func (n *Node[T]) Meta() any {
+	return n.meta
+}
+
+// InsertLeft inserts a new node to the left. An existing left child
+// is detached from the node.
+func (n *Node[T]) InsertLeft(value T) *Node[T] {
+	return n.InsertLeftNode(NewNode(value))
 }
 
-// InsertRight inserts a new node to the right
+// InsertRight inserts a new node to the right. An existing right
+// child is detached from the node.
 func (n *Node[T]) InsertRight(value T) *Node[T] {
-	right := NewNode(value)
-	n.Right = right
+	return n.InsertRightNode(NewNode(value))
+}
+
+// InsertLeftNode attaches the given node along with its sub-tree as
+// the left child of the node, and returns it. An existing left child
+// is detached from the node, and the given node is detached from its
+// previous parent, if any. The given node must not be an ancestor of
+// the node.
+func (n *Node[T]) InsertLeftNode(child *Node[T]) *Node[T] {
+	n.attach(&n.Left, child)
+
+	return child
+}
+
+// InsertRightNode attaches the given node along with its sub-tree as
+// the right child of the node, and returns it. An existing right child
+// is detached from the node, and the given node is detached from its
+// previous parent, if any. The given node must not be an ancestor of
+// the node.
+func (n *Node[T]) InsertRightNode(child *Node[T]) *Node[T] {
+	n.attach(&n.Right, child)
+
+	return child
+}
+
+// attach sets the given child link of the node to the given child,
+// updating the parent links and the cached heights and sizes. The
+// cached bounds of the node and its ancestors are invalidated.
+func (n *Node[T]) attach(link **Node[T], child *Node[T]) {
+	if *link != nil && (*link).parent == n {
+		(*link).parent = nil
+	}
+
+	if child != nil {
+		if child.parent != nil {
+			child.Remove()
+		}
+		child.parent = n
+		if n.heightCached && !child.heightCached {
+			child.RecomputeHeights()
+		}
+		if n.sizeCached && !child.sizeCached {
+			child.RecomputeSizes()
+		}
+	}
 
-	return right
+	*link = child
+	n.updateCaches()
+	n.invalidateBounds()
 }
 
 // WalkInOrder performs an iterative In-order walking of the binary
 // tree - Left-Node-Right (LNR)
 func (n *Node[T]) WalkInOrder(walkFunc WalkFunc[T]) error {
-	stack := deque.New[*Node[T]]()
+	return stopWalk(n.walkInOrder(n.shouldSkipNode, walkFunc))
+}
+
+// walkInOrder walks the tree in in-order, skipping the sub-trees
+// of nodes for which skip returns true.
+func (n *Node[T]) walkInOrder(skip SkipNodeFunc[T], walkFunc WalkFunc[T]) error {
+	stack := newRingDeque[*Node[T]]()
 	node := n
 
 	for node != nil || !stack.IsEmpty() {
 		for node != nil {
-			if n.shouldSkipNode(node) {
+			if skip(node) {
 				node = nil
 				break
 			}
-			stack.PushFront(node)
+			stack.PushBack(node)
 			node = node.Left
 		}
 
 		if !stack.IsEmpty() {
-			item, err := stack.PopFront()
+			item, err := stack.PopBack()
 			if err != nil {
 				panic(err)
 			}
@@ -159,19 +393,111 @@ func (n *Node[T]) WalkInOrder(walkFunc WalkFunc[T]) error {
 	return nil
 }
 
+// WalkInOrderMorris performs an In-order walking of the binary tree -
+// Left-Node-Right (LNR), using Morris traversal. The tree is
+// temporarily modified by threading the right links of in-order
+// predecessors, which allows walking the tree with constant auxiliary
+// space. The threads are removed before returning, even when
+// walkFunc returns an error, in which case the walk continues without
+// invoking walkFunc until the tree is restored.
+//
+// Since no stack of ancestors is kept, the skip node handlers are not
+// honored by this walk.
+func (n *Node[T]) WalkInOrderMorris(walkFunc WalkFunc[T]) error {
+	var walkErr error
+	visit := func(node *Node[T]) {
+		if walkErr == nil {
+			walkErr = walkFunc(node)
+		}
+	}
+
+	node := n
+	for node != nil {
+		if node.Left == nil {
+			visit(node)
+			node = node.Right
+			continue
+		}
+
+		// Find the in-order predecessor of the node
+		pred := node.Left
+		for pred.Right != nil && pred.Right != node {
+			pred = pred.Right
+		}
+
+		if pred.Right == nil {
+			// Thread the predecessor back to the node
+			pred.Right = node
+			node = node.Left
+		} else {
+			// Remove the thread
+			pred.Right = nil
+			visit(node)
+			node = node.Right
+		}
+	}
+
+	return stopWalk(walkErr)
+}
+
+// WalkReverseInOrder performs an iterative Reverse In-order walking
+// of the binary tree - Right-Node-Left (RNL)
+func (n *Node[T]) WalkReverseInOrder(walkFunc WalkFunc[T]) error {
+	return stopWalk(n.walkReverseInOrder(n.shouldSkipNode, walkFunc))
+}
+
+// walkReverseInOrder walks the tree in reverse in-order, skipping the
+// sub-trees of nodes for which skip returns true.
+func (n *Node[T]) walkReverseInOrder(skip SkipNodeFunc[T], walkFunc WalkFunc[T]) error {
+	stack := newRingDeque[*Node[T]]()
+	node := n
+
+	for node != nil || !stack.IsEmpty() {
+		for node != nil {
+			if skip(node) {
+				node = nil
+				break
+			}
+			stack.PushBack(node)
+			node = node.Right
+		}
+
+		if !stack.IsEmpty() {
+			item, err := stack.PopBack()
+			if err != nil {
+				panic(err)
+			}
+
+			if err := walkFunc(item); err != nil {
+				return err
+			}
+
+			node = item.Left
+		}
+	}
+
+	return nil
+}
+
 // WalkPreOrder performs an iterative Pre-order walking of the
 // binary tree - Node-Left-Right (NLR)
 func (n *Node[T]) WalkPreOrder(walkFunc WalkFunc[T]) error {
-	stack := deque.New[*Node[T]]()
-	stack.PushFront(n)
+	return stopWalk(n.walkPreOrder(n.shouldSkipNode, walkFunc))
+}
+
+// walkPreOrder walks the tree in pre-order, skipping the sub-trees
+// of nodes for which skip returns true.
+func (n *Node[T]) walkPreOrder(skip SkipNodeFunc[T], walkFunc WalkFunc[T]) error {
+	stack := newRingDeque[*Node[T]]()
+	stack.PushBack(n)
 
 	for !stack.IsEmpty() {
-		node, err := stack.PopFront()
+		node, err := stack.PopBack()
 		if err != nil {
 			panic(err)
 		}
 
-		if n.shouldSkipNode(node) {
+		if skip(node) {
 			continue
 		}
 
@@ -180,11 +506,11 @@ func (n *Node[T]) WalkPreOrder(walkFunc WalkFunc[T]) error {
 		}
 
 		if node.Right != nil {
-			stack.PushFront(node.Right)
+			stack.PushBack(node.Right)
 		}
 
 		if node.Left != nil {
-			stack.PushFront(node.Left)
+			stack.PushBack(node.Left)
 		}
 	}
 
@@ -194,32 +520,38 @@ func (n *Node[T]) WalkPreOrder(walkFunc WalkFunc[T]) error {
 // WalkPostOrder performs an iterative Post-order walking of the
 // binary tree - Left-Right-Node (LRN)
 func (n *Node[T]) WalkPostOrder(walkFunc WalkFunc[T]) error {
-	stack := deque.New[*Node[T]]()
-	result := deque.New[*Node[T]]()
-	stack.PushFront(n)
+	return stopWalk(n.walkPostOrder(n.shouldSkipNode, walkFunc))
+}
+
+// walkPostOrder walks the tree in post-order, skipping the sub-trees
+// of nodes for which skip returns true.
+func (n *Node[T]) walkPostOrder(skip SkipNodeFunc[T], walkFunc WalkFunc[T]) error {
+	stack := newRingDeque[*Node[T]]()
+	result := newRingDeque[*Node[T]]()
+	stack.PushBack(n)
 
 	for !stack.IsEmpty() {
-		node, err := stack.PopFront()
+		node, err := stack.PopBack()
 		if err != nil {
 			panic(err)
 		}
 
-		if n.shouldSkipNode(node) {
+		if skip(node) {
 			continue
 		}
 
 		if node.Left != nil {
-			stack.PushFront(node.Left)
+			stack.PushBack(node.Left)
 		}
 		if node.Right != nil {
-			stack.PushFront(node.Right)
+			stack.PushBack(node.Right)
 		}
 
-		result.PushFront(node)
+		result.PushBack(node)
 	}
 
 	for !result.IsEmpty() {
-		node, err := result.PopFront()
+		node, err := result.PopBack()
 		if err != nil {
 			return err
 		}
@@ -234,7 +566,13 @@ func (n *Node[T]) WalkPostOrder(walkFunc WalkFunc[T]) error {
 // WalkLevelOrder performs an iterative Level-order (Breadth-first)
 // walking of the binary tree.
 func (n *Node[T]) WalkLevelOrder(walkFunc WalkFunc[T]) error {
-	queue := deque.New[*Node[T]]()
+	return stopWalk(n.walkLevelOrder(n.shouldSkipNode, walkFunc))
+}
+
+// walkLevelOrder walks the tree in level-order, skipping the sub-trees
+// of nodes for which skip returns true.
+func (n *Node[T]) walkLevelOrder(skip SkipNodeFunc[T], walkFunc WalkFunc[T]) error {
+	queue := newRingDeque[*Node[T]]()
 	queue.PushBack(n)
 
 	for !queue.IsEmpty() {
@@ -243,7 +581,7 @@ func (n *Node[T]) WalkLevelOrder(walkFunc WalkFunc[T]) error {
 			panic(err)
 		}
 
-		if n.shouldSkipNode(node) {
+		if skip(node) {
 			continue
 		}
 
@@ -262,368 +600,3297 @@ func (n *Node[T]) WalkLevelOrder(walkFunc WalkFunc[T]) error {
 	return nil
 }
 
-// Size returns the size of the tree
-func (n *Node[T]) Size() int {
-	size := 0
-	walkFunc := func(n *Node[T]) error {
-		size++
-		return nil
-	}
-	n.WalkInOrder(walkFunc)
-
-	return size
-}
+// ErrQueueLimitExceeded is returned by WalkLevelOrderBounded when the
+// number of queued nodes exceeds the given limit.
+var ErrQueueLimitExceeded = errors.New("queue limit exceeded")
 
-type nodeHeight[T any] struct {
-	node   *Node[T]
-	height int
-}
+// ErrInvalidQueueLimit is returned by WalkLevelOrderBounded when the
+// given limit is not positive.
+var ErrInvalidQueueLimit = errors.New("invalid queue limit")
 
-// Height returns the height of the tree
-func (n *Node[T]) Height() int {
-	max_height := 0
-	root := &nodeHeight[T]{
-		node:   n,
-		height: 0,
+// WalkLevelOrderBounded performs an iterative Level-order walking of
+// the binary tree, while keeping at most maxQueue nodes in the queue.
+// If the frontier of the walk grows beyond maxQueue nodes, the walk is
+// aborted and ErrQueueLimitExceeded is returned. ErrInvalidQueueLimit
+// is returned without visiting any node, if maxQueue is not positive.
+//
+// Note that level-order walking needs to queue up to an entire level
+// of the tree, so a bound lower than the widest level of the tree
+// makes it impossible to complete the walk. Nodes visited before the
+// limit is hit have already been passed to walkFunc.
+func (n *Node[T]) WalkLevelOrderBounded(maxQueue int, walkFunc WalkFunc[T]) error {
+	if maxQueue <= 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidQueueLimit, maxQueue)
 	}
-	stack := deque.New[*nodeHeight[T]]()
-	stack.PushFront(root)
 
-	for !stack.IsEmpty() {
-		item, err := stack.PopFront()
+	queue := newRingDeque[*Node[T]]()
+	queue.PushBack(n)
+
+	for !queue.IsEmpty() {
+		node, err := queue.PopFront()
 		if err != nil {
 			panic(err)
 		}
 
-		if item.height > max_height {
-			max_height = item.height
+		if n.shouldSkipNode(node) {
+			continue
 		}
 
-		if item.node.Right != nil {
-			right := &nodeHeight[T]{
-				node:   item.node.Right,
-				height: item.height + 1,
-			}
-			stack.PushFront(right)
-		}
-		if item.node.Left != nil {
-			left := &nodeHeight[T]{
-				node:   item.node.Left,
-				height: item.height + 1,
-			}
-			stack.PushFront(left)
+		if err := walkFunc(node); err != nil {
+			return stopWalk(err)
 		}
-	}
-
-	return max_height
-}
-
-// IsLeafNode returns true, if the node is a leaf, false otherwise.
-func (n *Node[T]) IsLeafNode() bool {
-	return n.Left == nil && n.Right == nil
-}
-
-// IsFullNode returns true, if the node contains left and right
-// children
-func (n *Node[T]) IsFullNode() bool {
-	return n.Left != nil && n.Right != nil
-}
 
-// AddSkipNodeFunc adds a new handler for determining whether a
-// node from the tree should be skipped while traversing it.
-func (n *Node[T]) AddSkipNodeFunc(handler SkipNodeFunc[T]) {
-	n.skipNodeFuncs = append(n.skipNodeFuncs, handler)
-}
+		for _, child := range []*Node[T]{node.Left, node.Right} {
+			if child == nil {
+				continue
+			}
 
-// shouldSkipNode applies the list of SkipNodeFunc handlers in
-// order to determine whether a node should be skipped while walking
-// the tree.
-func (n *Node[T]) shouldSkipNode(node *Node[T]) bool {
-	for _, handler := range n.skipNodeFuncs {
-		if handler(node) {
-			return true
+			if queue.Length() >= maxQueue {
+				return ErrQueueLimitExceeded
+			}
+			queue.PushBack(child)
 		}
 	}
 
-	return false
+	return nil
 }
 
-// Find looks for a node in the tree, which satisfies the given
-// predicate.
-func (n *Node[T]) FindNode(predicate FindFunc[T]) (*Node[T], bool) {
-	stack := deque.New[*Node[T]]()
-	stack.PushFront(n)
+// WalkPreOrderWithDepth performs an iterative Pre-order walking of
+// the binary tree - Node-Left-Right (NLR), passing the depth of each
+// node to walkFunc. The node the walk starts from is at depth 0.
+func (n *Node[T]) WalkPreOrderWithDepth(walkFunc WalkDepthFunc[T]) error {
+	stack := newRingDeque[*nodeHeight[T]]()
+	stack.PushBack(&nodeHeight[T]{node: n, height: 0})
 
 	for !stack.IsEmpty() {
-		node, err := stack.PopFront()
+		item, err := stack.PopBack()
 		if err != nil {
 			panic(err)
 		}
 
-		if predicate(node) {
-			return node, true
+		if n.shouldSkipNode(item.node) {
+			continue
 		}
 
-		if node.Right != nil {
-			stack.PushFront(node.Right)
-		}
-		if node.Left != nil {
-			stack.PushFront(node.Left)
+		if err := walkFunc(item.node, item.height); err != nil {
+			return stopWalk(err)
 		}
-	}
-
-	return nil, false
-}
-
-// IsFullTree returns true, if the binary tree is full. A full binary tree
-// is a tree in which every node has either 0 or 2 children.
-func (n *Node[T]) IsFullTree() bool {
-	stack := deque.New[*Node[T]]()
-	stack.PushFront(n)
 
-	for !stack.IsEmpty() {
-		node, err := stack.PopFront()
-		if err != nil {
-			panic(err)
-		}
-		if node.IsLeafNode() {
-			continue
+		if item.node.Right != nil {
+			stack.PushBack(&nodeHeight[T]{node: item.node.Right, height: item.height + 1})
 		}
-
-		if !node.IsFullNode() {
-			return false
+		if item.node.Left != nil {
+			stack.PushBack(&nodeHeight[T]{node: item.node.Left, height: item.height + 1})
 		}
-
-		stack.PushFront(node.Right)
-		stack.PushFront(node.Left)
 	}
 
-	return true
+	return nil
 }
 
-// IsDegenerateTree returns true, if each parent has only one child node.
-func (n *Node[T]) IsDegenerateTree() bool {
-	stack := deque.New[*Node[T]]()
-	stack.PushFront(n)
+// WalkLevelOrderWithDepth performs an iterative Level-order walking
+// of the binary tree, passing the depth of each node to walkFunc. The
+// node the walk starts from is at depth 0.
+func (n *Node[T]) WalkLevelOrderWithDepth(walkFunc WalkDepthFunc[T]) error {
+	queue := newRingDeque[*nodeHeight[T]]()
+	queue.PushBack(&nodeHeight[T]{node: n, height: 0})
 
-	for !stack.IsEmpty() {
-		node, err := stack.PopFront()
+	for !queue.IsEmpty() {
+		item, err := queue.PopFront()
 		if err != nil {
 			panic(err)
 		}
-		if node.IsLeafNode() {
+
+		if n.shouldSkipNode(item.node) {
 			continue
 		}
 
-		if node.IsFullNode() {
-			return false
+		if err := walkFunc(item.node, item.height); err != nil {
+			return stopWalk(err)
 		}
 
-		if node.Left != nil {
-			stack.PushFront(node.Left)
+		if item.node.Left != nil {
+			queue.PushBack(&nodeHeight[T]{node: item.node.Left, height: item.height + 1})
 		}
-		if node.Right != nil {
-			stack.PushFront(node.Right)
+		if item.node.Right != nil {
+			queue.PushBack(&nodeHeight[T]{node: item.node.Right, height: item.height + 1})
 		}
 	}
 
-	return true
+	return nil
 }
 
-// IsBalancedTree returns true, if the tree is balanced. A balanced tree
-// is such a tree, for which the height of the left and right
-// sub-trees of each node differ by no more than 1.
-func (n *Node[T]) IsBalancedTree() bool {
+// WalkLevelOrderWithMarker performs an iterative Level-order walking
+// of the binary tree, invoking nodeFunc for each node and endOfLevel
+// whenever all nodes from a level have been visited. Levels are
+// numbered starting from 0 for the node the walk starts from.
+func (n *Node[T]) WalkLevelOrderWithMarker(nodeFunc WalkFunc[T], endOfLevel func(level int) error) error {
+	queue := newRingDeque[*Node[T]]()
+	queue.PushBack(n)
+
+	for level := 0; !queue.IsEmpty(); level++ {
+		visited := 0
+		for size := queue.Length(); size > 0; size-- {
+			node, err := queue.PopFront()
+			if err != nil {
+				panic(err)
+			}
+
+			if n.shouldSkipNode(node) {
+				continue
+			}
+
+			if err := nodeFunc(node); err != nil {
+				return stopWalk(err)
+			}
+			visited++
+
+			if node.Left != nil {
+				queue.PushBack(node.Left)
+			}
+			if node.Right != nil {
+				queue.PushBack(node.Right)
+			}
+		}
+
+		if visited == 0 {
+			break
+		}
+
+		if err := endOfLevel(level); err != nil {
+			return stopWalk(err)
+		}
+	}
+
+	return nil
+}
+
+// WalkLevelOrderBottomUp performs an iterative reverse Level-order
+// walking of the binary tree, where levels are visited from the
+// deepest one up to the root, and nodes within each level are visited
+// from left to right.
+func (n *Node[T]) WalkLevelOrderBottomUp(walkFunc WalkFunc[T]) error {
+	levels := make([][]*Node[T], 0)
+	queue := newRingDeque[*Node[T]]()
+	queue.PushBack(n)
+
+	for !queue.IsEmpty() {
+		level := make([]*Node[T], 0, queue.Length())
+		for size := queue.Length(); size > 0; size-- {
+			node, err := queue.PopFront()
+			if err != nil {
+				panic(err)
+			}
+
+			if n.shouldSkipNode(node) {
+				continue
+			}
+
+			level = append(level, node)
+			if node.Left != nil {
+				queue.PushBack(node.Left)
+			}
+			if node.Right != nil {
+				queue.PushBack(node.Right)
+			}
+		}
+		levels = append(levels, level)
+	}
+
+	for i := len(levels) - 1; i >= 0; i-- {
+		for _, node := range levels[i] {
+			if err := walkFunc(node); err != nil {
+				return stopWalk(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// withContext returns a WalkFunc, which checks whether the context
+// is done before invoking walkFunc.
+func withContext[T any](ctx context.Context, walkFunc WalkFunc[T]) WalkFunc[T] {
+	f := func(node *Node[T]) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return walkFunc(node)
+	}
+
+	return f
+}
+
+// WalkInOrderContext performs an iterative In-order walking of the
+// binary tree, which stops with the context error as soon as the
+// context is done.
+func (n *Node[T]) WalkInOrderContext(ctx context.Context, walkFunc WalkFunc[T]) error {
+	return n.WalkInOrder(withContext(ctx, walkFunc))
+}
+
+// WalkPreOrderContext performs an iterative Pre-order walking of the
+// binary tree, which stops with the context error as soon as the
+// context is done.
+func (n *Node[T]) WalkPreOrderContext(ctx context.Context, walkFunc WalkFunc[T]) error {
+	return n.WalkPreOrder(withContext(ctx, walkFunc))
+}
+
+// WalkPostOrderContext performs an iterative Post-order walking of the
+// binary tree, which stops with the context error as soon as the
+// context is done.
+func (n *Node[T]) WalkPostOrderContext(ctx context.Context, walkFunc WalkFunc[T]) error {
+	return n.WalkPostOrder(withContext(ctx, walkFunc))
+}
+
+// WalkLevelOrderContext performs an iterative Level-order walking of
+// the binary tree, which stops with the context error as soon as the
+// context is done.
+func (n *Node[T]) WalkLevelOrderContext(ctx context.Context, walkFunc WalkFunc[T]) error {
+	return n.WalkLevelOrder(withContext(ctx, walkFunc))
+}
+
+// withProgress returns a WalkFunc, which invokes walkFunc and then
+// reports the number of nodes visited so far to progress after every
+// N-th visited node. Progress is never reported if every is not
+// positive.
+func withProgress[T any](walkFunc WalkFunc[T], every int, progress func(count int)) WalkFunc[T] {
+	count := 0
+	f := func(node *Node[T]) error {
+		if err := walkFunc(node); err != nil {
+			return err
+		}
+
+		count++
+		if every > 0 && count%every == 0 {
+			progress(count)
+		}
+		return nil
+	}
+
+	return f
+}
+
+// WalkInOrderProgress performs an iterative In-order walking of the
+// binary tree, invoking progress with the number of nodes visited so
+// far after every N-th node. A node is counted once walkFunc returns
+// for it without an error.
+func (n *Node[T]) WalkInOrderProgress(walkFunc WalkFunc[T], every int, progress func(count int)) error {
+	return n.WalkInOrder(withProgress(walkFunc, every, progress))
+}
+
+// WalkPreOrderProgress performs an iterative Pre-order walking of the
+// binary tree, invoking progress with the number of nodes visited so
+// far after every N-th node.
+func (n *Node[T]) WalkPreOrderProgress(walkFunc WalkFunc[T], every int, progress func(count int)) error {
+	return n.WalkPreOrder(withProgress(walkFunc, every, progress))
+}
+
+// WalkPostOrderProgress performs an iterative Post-order walking of
+// the binary tree, invoking progress with the number of nodes visited
+// so far after every N-th node.
+func (n *Node[T]) WalkPostOrderProgress(walkFunc WalkFunc[T], every int, progress func(count int)) error {
+	return n.WalkPostOrder(withProgress(walkFunc, every, progress))
+}
+
+// WalkLevelOrderProgress performs an iterative Level-order walking of
+// the binary tree, invoking progress with the number of nodes visited
+// so far after every N-th node.
+func (n *Node[T]) WalkLevelOrderProgress(walkFunc WalkFunc[T], every int, progress func(count int)) error {
+	return n.WalkLevelOrder(withProgress(walkFunc, every, progress))
+}
+
+// walk walks the tree in the given order, skipping the sub-trees of
+// nodes for which skip returns true.
+func (n *Node[T]) walk(order TraversalOrder, skip SkipNodeFunc[T], walkFunc WalkFunc[T]) error {
+	switch order {
+	case InOrder:
+		return n.walkInOrder(skip, walkFunc)
+	case PreOrder:
+		return n.walkPreOrder(skip, walkFunc)
+	case PostOrder:
+		return n.walkPostOrder(skip, walkFunc)
+	case LevelOrder:
+		return n.walkLevelOrder(skip, walkFunc)
+	default:
+		return ErrInvalidTraversalOrder
+	}
+}
+
+// WalkPruned walks the tree in the given order. Nodes for which prune
+// returns true are neither visited, nor are their sub-trees
+// descended into. The registered skip node handlers are honored as
+// well.
+func (n *Node[T]) WalkPruned(order TraversalOrder, prune FindFunc[T], visit WalkFunc[T]) error {
+	skip := func(node *Node[T]) bool {
+		return n.shouldSkipNode(node) || prune(node)
+	}
+
+	return stopWalk(n.walk(order, skip, visit))
+}
+
+// Size returns the number of nodes in the tree. The skip node
+// handlers are not honored, see SizeWalked for a variant which
+// honors them. Size is O(1) when the sizes are cached, see
+// RecomputeSizes.
+func (n *Node[T]) Size() int {
+	if n.sizeCached {
+		return n.size
+	}
+
+	return countAll(n)
+}
+
+// countAll returns the number of nodes in the sub-tree rooted at node.
+// The nodes are counted recursively, which avoids the heap
+// allocations done by the walkers.
+func countAll[T any](node *Node[T]) int {
+	if node == nil {
+		return 0
+	}
+
+	return 1 + countAll(node.Left) + countAll(node.Right)
+}
+
+// SizeWalked returns the number of nodes in the tree, excluding the
+// sub-trees of the skipped nodes.
+func (n *Node[T]) SizeWalked() int {
+	return n.sizeWalked(n)
+}
+
+// sizeWalked returns the number of nodes in the sub-tree rooted at
+// node, excluding the skipped ones. The nodes are counted
+// recursively, which avoids the heap allocations done by the walkers.
+func (n *Node[T]) sizeWalked(node *Node[T]) int {
+	if node == nil || n.shouldSkipNode(node) {
+		return 0
+	}
+
+	return 1 + n.sizeWalked(node.Left) + n.sizeWalked(node.Right)
+}
+
+// cachedSize returns the cached size of the given node, or 0 if the
+// node is nil.
+func cachedSize[T any](node *Node[T]) int {
+	if node == nil {
+		return 0
+	}
+
+	return node.size
+}
+
+// RecomputeSizes computes and caches the size of each sub-tree within
+// the tree rooted at the node, which makes Size O(1). Once cached, the
+// sizes are maintained in the same way as the cached heights, see
+// RecomputeHeights. Mutating the tree directly, e.g. via the
+// Left/Right fields, bypasses the cache, in which case RecomputeSizes
+// should be called again.
+func (n *Node[T]) RecomputeSizes() {
+	walkFunc := func(node *Node[T]) error {
+		node.size = cachedSize(node.Left) + cachedSize(node.Right) + 1
+		node.sizeCached = true
+		return nil
+	}
+
+	if err := n.walkPostOrder(noSkipNode[T], walkFunc); err != nil {
+		panic(err)
+	}
+}
+
+type nodeHeight[T any] struct {
+	node   *Node[T]
+	height int
+}
+
+// Height returns the height of the tree, i.e. the number of edges on
+// the longest path from the node down to a leaf node. Height is O(1)
+// when the heights are cached, see RecomputeHeights.
+func (n *Node[T]) Height() int {
+	if n.heightCached {
+		return n.height
+	}
+
+	max_height := 0
+	root := &nodeHeight[T]{
+		node:   n,
+		height: 0,
+	}
+	stack := newRingDeque[*nodeHeight[T]]()
+	stack.PushFront(root)
+
+	for !stack.IsEmpty() {
+		item, err := stack.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		if item.height > max_height {
+			max_height = item.height
+		}
+
+		if item.node.Right != nil {
+			right := &nodeHeight[T]{
+				node:   item.node.Right,
+				height: item.height + 1,
+			}
+			stack.PushFront(right)
+		}
+		if item.node.Left != nil {
+			left := &nodeHeight[T]{
+				node:   item.node.Left,
+				height: item.height + 1,
+			}
+			stack.PushFront(left)
+		}
+	}
+
+	return max_height
+}
+
+// cachedHeight returns the cached height of the given node, or -1 if
+// the node is nil.
+func cachedHeight[T any](node *Node[T]) int {
+	if node == nil {
+		return -1
+	}
+
+	return node.height
+}
+
+// RecomputeHeights computes and caches the height of each sub-tree
+// within the tree rooted at the node, which makes Height O(1) and
+// IsBalancedTree O(n). Once cached, the heights are maintained by
+// InsertLeft, InsertRight, Remove, the rotations and the BST
+// operations, at the cost of walking up the ancestors of the modified
+// node, which requires valid parent links. Mutating the tree
+// directly, e.g. via the Left/Right fields, bypasses the cache, in
+// which case RecomputeHeights should be called again.
+func (n *Node[T]) RecomputeHeights() {
+	walkFunc := func(node *Node[T]) error {
+		node.height = max(cachedHeight(node.Left), cachedHeight(node.Right)) + 1
+		node.heightCached = true
+		return nil
+	}
+
+	if err := n.walkPostOrder(noSkipNode[T], walkFunc); err != nil {
+		panic(err)
+	}
+}
+
+// updateCaches recomputes the cached heights and sizes of the node and
+// its ancestors from the cached values of their children. It does
+// nothing, if neither the heights, nor the sizes are cached.
+func (n *Node[T]) updateCaches() {
+	for node := n; node != nil && (node.heightCached || node.sizeCached); node = node.parent {
+		if node.heightCached {
+			node.height = max(cachedHeight(node.Left), cachedHeight(node.Right)) + 1
+		}
+		if node.sizeCached {
+			node.size = cachedSize(node.Left) + cachedSize(node.Right) + 1
+		}
+	}
+}
+
+// MinDepth returns the number of edges on the shortest path from the
+// node down to a leaf node.
+func (n *Node[T]) MinDepth() int {
+	_, depth := n.NearestLeaf()
+
+	return depth
+}
+
+// NearestLeaf returns the leaf node closest to the node, along with
+// the number of edges between them. When multiple leaves are at the
+// same distance, the left-most one is returned.
+func (n *Node[T]) NearestLeaf() (*Node[T], int) {
+	queue := newRingDeque[*nodeHeight[T]]()
+	queue.PushBack(&nodeHeight[T]{node: n, height: 0})
+
+	for {
+		item, err := queue.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		if item.node.IsLeafNode() {
+			return item.node, item.height
+		}
+
+		if item.node.Left != nil {
+			queue.PushBack(&nodeHeight[T]{node: item.node.Left, height: item.height + 1})
+		}
+		if item.node.Right != nil {
+			queue.PushBack(&nodeHeight[T]{node: item.node.Right, height: item.height + 1})
+		}
+	}
+}
+
+// IsLeafNode returns true, if the node is a leaf, false otherwise.
+func (n *Node[T]) IsLeafNode() bool {
+	return n.Left == nil && n.Right == nil
+}
+
+// IsFullNode returns true, if the node contains left and right
+// children
+func (n *Node[T]) IsFullNode() bool {
+	return n.Left != nil && n.Right != nil
+}
+
+// noSkipNode is a SkipNodeFunc, which never skips a node.
+func noSkipNode[T any](node *Node[T]) bool {
+	return false
+}
+
+// countNodes returns the number of nodes, which satisfy the given
+// predicate.
+func (n *Node[T]) countNodes(predicate FindFunc[T]) int {
+	count := 0
+	walkFunc := func(node *Node[T]) error {
+		if predicate(node) {
+			count++
+		}
+		return nil
+	}
+
+	if err := n.WalkPreOrder(walkFunc); err != nil {
+		panic(err)
+	}
+
+	return count
+}
+
+// CountLeaves returns the number of leaf nodes in the tree.
+func (n *Node[T]) CountLeaves() int {
+	return n.countNodes((*Node[T]).IsLeafNode)
+}
+
+// CountInternalNodes returns the number of internal (non-leaf) nodes
+// in the tree.
+func (n *Node[T]) CountInternalNodes() int {
+	internal := func(node *Node[T]) bool {
+		return !node.IsLeafNode()
+	}
+
+	return n.countNodes(internal)
+}
+
+// AddSkipNodeFunc adds a new handler for determining whether a
+// node from the tree should be skipped while traversing it.
+//
+// The handlers are registered per node, and a walk honors only the
+// handlers of the node it has been started from, including for the
+// starting node itself. Handlers are not inherited from the
+// ancestors of the node, i.e. walking a sub-tree rooted at a child
+// node does not apply the handlers registered on the root, and the
+// handlers registered on the descendants are not consulted either.
+func (n *Node[T]) AddSkipNodeFunc(handler SkipNodeFunc[T]) {
+	n.skipNodeFuncs = append(n.skipNodeFuncs, handler)
+}
+
+// shouldSkipNode applies the list of SkipNodeFunc handlers in
+// order to determine whether a node should be skipped while walking
+// the tree.
+func (n *Node[T]) shouldSkipNode(node *Node[T]) bool {
+	for _, handler := range n.skipNodeFuncs {
+		if handler(node) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Find looks for a node in the tree, which satisfies the given
+// predicate.
+func (n *Node[T]) FindNode(predicate FindFunc[T]) (*Node[T], bool) {
+	stack := newRingDeque[*Node[T]]()
+	stack.PushFront(n)
+
+	for !stack.IsEmpty() {
+		node, err := stack.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		if predicate(node) {
+			return node, true
+		}
+
+		if node.Right != nil {
+			stack.PushFront(node.Right)
+		}
+		if node.Left != nil {
+			stack.PushFront(node.Left)
+		}
+	}
+
+	return nil, false
+}
+
+// FindAll returns all nodes in the tree, which satisfy the given
+// predicate, in pre-order. An empty slice is returned, if no node
+// satisfies the predicate.
+func (n *Node[T]) FindAll(predicate FindFunc[T]) []*Node[T] {
+	result := make([]*Node[T], 0)
+	walkFunc := func(node *Node[T]) error {
+		if predicate(node) {
+			result = append(result, node)
+		}
+		return nil
+	}
+
+	if err := n.WalkPreOrder(walkFunc); err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+// IsFullTree returns true, if the binary tree is full. A full binary tree
+// is a tree in which every node has either 0 or 2 children.
+func (n *Node[T]) IsFullTree() bool {
+	stack := newRingDeque[*Node[T]]()
+	stack.PushFront(n)
+
+	for !stack.IsEmpty() {
+		node, err := stack.PopFront()
+		if err != nil {
+			panic(err)
+		}
+		if node.IsLeafNode() {
+			continue
+		}
+
+		if !node.IsFullNode() {
+			return false
+		}
+
+		stack.PushFront(node.Right)
+		stack.PushFront(node.Left)
+	}
+
+	return true
+}
+
+// IsDegenerateTree returns true, if each parent has only one child node.
+func (n *Node[T]) IsDegenerateTree() bool {
+	stack := newRingDeque[*Node[T]]()
+	stack.PushFront(n)
+
+	for !stack.IsEmpty() {
+		node, err := stack.PopFront()
+		if err != nil {
+			panic(err)
+		}
+		if node.IsLeafNode() {
+			continue
+		}
+
+		if node.IsFullNode() {
+			return false
+		}
+
+		if node.Left != nil {
+			stack.PushFront(node.Left)
+		}
+		if node.Right != nil {
+			stack.PushFront(node.Right)
+		}
+	}
+
+	return true
+}
+
+// IsBalancedTree returns true, if the tree is balanced. A balanced tree
+// is such a tree, for which the height of the left and right
+// sub-trees of each node differ by no more than 1. IsBalancedTree is
+// O(n) when the heights are cached, see RecomputeHeights.
+func (n *Node[T]) IsBalancedTree() bool {
 	if n.IsLeafNode() {
 		return true
 	}
 
-	stack := deque.New[*Node[T]]()
-	stack.PushFront(n)
+	stack := newRingDeque[*Node[T]]()
+	stack.PushFront(n)
+
+	for !stack.IsEmpty() {
+		node, err := stack.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		left_height := -1
+		if node.Left != nil {
+			left_height = node.Left.Height()
+			stack.PushFront(node.Left)
+		}
+
+		right_height := -1
+		if node.Right != nil {
+			right_height = node.Right.Height()
+			stack.PushFront(node.Right)
+		}
+
+		left_height += 1
+		right_height += 1
+		diff := left_height - right_height
+		if diff < 0 {
+			diff = -diff
+		}
+
+		if diff > 1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsCompleteTree returns true, if the tree is complete. A complete
+// binary tree is a binary tree in which every level, except possibly
+// the last, is completely filled, and all nodes in the last level are
+// as far left as possible.
+func (n *Node[T]) IsCompleteTree() bool {
+	if n.IsLeafNode() {
+		return true
+	}
+
+	nonFullNodeSeen := false
+	queue := newRingDeque[*Node[T]]()
+	queue.PushBack(n)
+
+	for !queue.IsEmpty() {
+		node, err := queue.PopFront()
+		if err != nil {
+			panic(err)
+
+		}
+
+		if node.Left != nil {
+			if nonFullNodeSeen {
+				return false
+			}
+			queue.PushBack(node.Left)
+		}
+
+		if !node.IsFullNode() {
+			nonFullNodeSeen = true
+		}
+
+		if node.Right != nil {
+			if nonFullNodeSeen {
+				return false
+			}
+			queue.PushBack(node.Right)
+		}
+	}
+
+	return true
+}
+
+// IsHeapShaped returns true, if the tree has the shape required by a
+// binary heap, i.e. the tree is complete. It is the shape check used
+// by IsMinHeap and IsMaxHeap.
+func (n *Node[T]) IsHeapShaped() bool {
+	return n.IsCompleteTree()
+}
+
+// isHeapOrdered returns true, if for each node the result of
+// comparing the node with its children has the given sign.
+func (n *Node[T]) isHeapOrdered(cmp ComparatorFunc[T], sign int) bool {
+	stack := newRingDeque[*Node[T]]()
+	stack.PushFront(n)
+
+	for !stack.IsEmpty() {
+		node, err := stack.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		for _, child := range []*Node[T]{node.Left, node.Right} {
+			if child == nil {
+				continue
+			}
+
+			result := cmp(node.Value, child.Value)
+			if result == Incomparable || result*sign < 0 {
+				return false
+			}
+			stack.PushFront(child)
+		}
+	}
+
+	return true
+}
+
+// IsMinHeap returns true, if the tree is heap shaped and the value of
+// each node is less than or equal to the values of its children.
+func (n *Node[T]) IsMinHeap(cmp ComparatorFunc[T]) bool {
+	return n.IsHeapShaped() && n.isHeapOrdered(cmp, -1)
+}
+
+// IsMaxHeap returns true, if the tree is heap shaped and the value of
+// each node is greater than or equal to the values of its children.
+func (n *Node[T]) IsMaxHeap(cmp ComparatorFunc[T]) bool {
+	return n.IsHeapShaped() && n.isHeapOrdered(cmp, 1)
+}
+
+// IsPerfectTree returns true, if the binary tree is full and complete
+func (n *Node[T]) IsPerfectTree() bool {
+	return n.IsFullTree() && n.IsCompleteTree()
+}
+
+// errNotBst is returned by a walking function when a tree being
+// walked is detected to not be a BST.
+var errNotBst = errors.New("not a binary search tree")
+
+// IsBinarySearchTree returns true, if the tree is a Binary Search
+// Tree (BST). Consecutive nodes in in-order, which are reported as
+// Incomparable by the comparator, violate the BST ordering.
+func (n *Node[T]) IsBinarySearchTree(comparator ComparatorFunc[T]) bool {
+	if n.IsLeafNode() {
+		return true
+	}
+
+	// Use errNotBst to signal a condition to stop walking the
+	// tree as soon as we know this is a not a BST.
+	var last *Node[T]
+	walkFunc := func(curr *Node[T]) error {
+		if last != nil {
+			result := comparator(last.Value, curr.Value)
+			if result == Incomparable || result > 0 {
+				return errNotBst
+			}
+		}
+		last = curr
+
+		return nil
+	}
+
+	err := n.WalkInOrder(walkFunc)
+	switch {
+	case err == errNotBst:
+		return false
+	case err != nil:
+		panic(err)
+	default:
+		return true
+	}
+}
+
+// AddAttribute associates an attribute with the node, which will be
+// used when generating the Dot representation of the tree.
+func (n *Node[T]) AddAttribute(name, value string) {
+	n.dotAttributes[name] = value
+}
+
+// SetAttributeWhere associates the attribute with each node in the
+// tree, which satisfies the given predicate, and returns the number
+// of updated nodes. The sub-trees of the skipped nodes are not
+// examined.
+func (n *Node[T]) SetAttributeWhere(name, value string, predicate FindFunc[T]) int {
+	return n.countNodes(func(node *Node[T]) bool {
+		if !predicate(node) {
+			return false
+		}
+
+		node.AddAttribute(name, value)
+		return true
+	})
+}
+
+// GetAttribute returns the value of the attribute with the given
+// name. The bool result is false, if the node has no such attribute.
+func (n *Node[T]) GetAttribute(name string) (string, bool) {
+	value, ok := n.dotAttributes[name]
+	return value, ok
+}
+
+// RemoveAttribute removes the attribute with the given name from the
+// node, if present.
+func (n *Node[T]) RemoveAttribute(name string) {
+	delete(n.dotAttributes, name)
+}
+
+// ClearAttributes removes all attributes associated with the node,
+// e.g. in order to reset the highlighting between renders of the
+// tree.
+func (n *Node[T]) ClearAttributes() {
+	clear(n.dotAttributes)
+}
+
+// HasAttribute returns true, if the node has an attribute with the
+// given name.
+func (n *Node[T]) HasAttribute(name string) bool {
+	_, ok := n.dotAttributes[name]
+	return ok
+}
+
+// GetDotAttributes returns the attributes associated with the node in
+// format suitable for using in the Dot representation. Values, which
+// are not plain identifiers, numerals or HTML strings, are quoted and
+// escaped. The attributes are sorted by name, so that the output is
+// stable.
+func (n *Node[T]) GetDotAttributes() string {
+	attrs := ""
+	for _, k := range slices.Sorted(maps.Keys(n.dotAttributes)) {
+		attrs += fmt.Sprintf("%s=%s ", k, dotValue(n.dotAttributes[k]))
+	}
+
+	return strings.TrimRight(attrs, " ")
+}
+
+// nodeIds assigns sequential ids to the nodes in the order in which
+// they are first encountered, which keeps the generated
+// representations of a tree reproducible.
+type nodeIds[T any] map[*Node[T]]int
+
+// get returns the id of the given node, assigning the next id in
+// sequence if the node has not been encountered yet.
+func (ids nodeIds[T]) get(node *Node[T]) int {
+	id, ok := ids[node]
+	if !ok {
+		id = len(ids) + 1
+		ids[node] = id
+	}
+
+	return id
+}
+
+// DotOptions configures the Dot representation of a tree. The zero
+// value yields the default representation.
+type DotOptions struct {
+	// Plain specifies whether to render nodes with a plain shape
+	// instead of records, in which case edges are emitted without
+	// ports.
+	Plain bool
+
+	// RankDir specifies the direction of the graph layout, e.g. "TB"
+	// for top-to-bottom, or "LR" for left-to-right. The Graphviz
+	// default is used, if empty.
+	RankDir string
+
+	// NodeShape specifies the shape of the nodes. Edges are emitted
+	// with ports unless Plain is set, which requires a record-based
+	// shape, e.g. "record" or "Mrecord". Defaults to "record".
+	NodeShape string
+
+	// NodeColor specifies the color of the nodes. Defaults to
+	// "lightblue".
+	NodeColor string
+
+	// GraphName specifies the name of the graph. The graph is
+	// anonymous, if empty.
+	GraphName string
+
+	// NilPlaceholders specifies whether to emit invisible placeholder
+	// nodes and edges in place of the missing child of nodes with a
+	// single child, so that Graphviz keeps the left and right
+	// positioning of the children.
+	NilPlaceholders bool
+}
+
+// dotNodeAttrs returns the attributes of nodes in the Dot
+// representation configured with the given options.
+func dotNodeAttrs(opts DotOptions) string {
+	color := "lightblue"
+	if opts.NodeColor != "" {
+		color = dotValue(opts.NodeColor)
+	}
+
+	shape := ""
+	switch {
+	case opts.NodeShape != "":
+		shape = fmt.Sprintf("shape=%s ", dotValue(opts.NodeShape))
+	case !opts.Plain:
+		shape = "shape=record "
+	}
+
+	style := `style="filled, rounded"`
+	if opts.Plain {
+		style = "style=filled"
+	}
+
+	return fmt.Sprintf("[color=%s fillcolor=%s fontcolor=black %s%s]", color, color, shape, style)
+}
+
+// writeDotHeader writes the beginning of a Dot graph.
+func writeDotHeader(w io.Writer, opts DotOptions) error {
+	header := "digraph {"
+	if opts.GraphName != "" {
+		header = fmt.Sprintf("digraph %s {", dotValue(opts.GraphName))
+	}
+
+	if _, err := fmt.Fprintln(w, header); err != nil {
+		return err
+	}
+
+	if opts.RankDir != "" {
+		if _, err := fmt.Fprintf(w, "\trankdir=%s\n", dotValue(opts.RankDir)); err != nil {
+			return err
+		}
+	}
+
+	// Keep the placeholders of the missing children in place
+	if opts.NilPlaceholders {
+		if _, err := fmt.Fprintln(w, "\tordering=out"); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "\tnode %s\n", dotNodeAttrs(opts)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// writeDotFooter writes the end of a Dot graph.
+func writeDotFooter(w io.Writer) error {
+	_, err := fmt.Fprintln(w, "}")
+
+	return err
+}
+
+// dotEscaper escapes quoted strings for use in Dot.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`)
+
+// dotRecordEscaper escapes the labels of record nodes for use in
+// Dot. In addition to the escaping done by dotEscaper, the field
+// separators and delimiters of records are escaped, so that the label
+// is rendered as a single field.
+var dotRecordEscaper = strings.NewReplacer(
+	`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`,
+	`|`, `\|`, `<`, `\<`, `>`, `\>`, `{`, `\{`, `}`, `\}`,
+)
+
+// dotIdPattern matches the values, which can be used in Dot without
+// quoting, i.e. identifiers and numerals. HTML strings are matched by
+// isDotHTML.
+var dotIdPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*|-?(\.[0-9]+|[0-9]+(\.[0-9]*)?))$`)
+
+// isDotHTML returns true, if the given value is a Dot HTML string,
+// i.e. it is enclosed in angle brackets, and the angle brackets
+// within are balanced.
+func isDotHTML(value string) bool {
+	if !strings.HasPrefix(value, "<") {
+		return false
+	}
+
+	depth := 0
+	for i, c := range value {
+		switch c {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		}
+
+		// The opening bracket may be closed by the last one only
+		if depth == 0 && i < len(value)-1 {
+			return false
+		}
+	}
+
+	return depth == 0
+}
+
+// dotValue returns the given attribute value in a form suitable for
+// using in Dot, quoting and escaping it when needed.
+func dotValue(value string) string {
+	if dotIdPattern.MatchString(value) || isDotHTML(value) {
+		return value
+	}
+
+	return `"` + dotEscaper.Replace(value) + `"`
+}
+
+// defaultDotLabel returns the default label of a node in the Dot
+// representation.
+func defaultDotLabel[T any](node *Node[T]) string {
+	return fmt.Sprintf("%v", node.Value)
+}
+
+// writeDotPlaceholder writes an invisible placeholder node along with
+// an invisible edge in place of the missing child of the given node
+// on the given side, i.e. "l" or "r".
+func writeDotPlaceholder(w io.Writer, opts DotOptions, nodeId int, side string) error {
+	placeholderId := fmt.Sprintf("nil_%d_%s", nodeId, side)
+	if _, err := fmt.Fprintf(w, "\t%s [label=\"\" style=invis]\n", placeholderId); err != nil {
+		return err
+	}
+
+	from := fmt.Sprintf("%d:%s", nodeId, side)
+	if opts.Plain {
+		from = fmt.Sprintf("%d", nodeId)
+	}
+
+	_, err := fmt.Fprintf(w, "\t%s -> %s [style=invis]\n", from, placeholderId)
+
+	return err
+}
+
+// writeDotNodes writes the nodes and edges of the tree in Dot format,
+// using label to generate the labels of the nodes, and ids to
+// identify them.
+func (n *Node[T]) writeDotNodes(w io.Writer, opts DotOptions, label func(*Node[T]) string, ids nodeIds[T]) error {
+	labelFormat := "\t%d [label=\"<l>|<v> %s|<r>\" %s]\n"
+	leftFormat := "\t%d:l -> %d:v\n"
+	rightFormat := "\t%d:r -> %d:v\n"
+	escaper := dotRecordEscaper
+	if opts.Plain {
+		escaper = dotEscaper
+		labelFormat = "\t%d [label=\"%s\" %s]\n"
+		leftFormat = "\t%d -> %d\n"
+		rightFormat = "\t%d -> %d\n"
+	}
+
+	walkFunc := func(n *Node[T]) error {
+		nodeId := ids.get(n)
+		_, err := fmt.Fprintf(w, labelFormat, nodeId, escaper.Replace(label(n)), n.GetDotAttributes())
+		if err != nil {
+			return err
+		}
+
+		// The placeholder of a missing child is emitted in place of
+		// its edge, so that the children are laid out in edge order.
+		placeholders := opts.NilPlaceholders && (n.Left == nil) != (n.Right == nil)
+		if n.Left != nil {
+			if _, err := fmt.Fprintf(w, leftFormat, nodeId, ids.get(n.Left)); err != nil {
+				return err
+			}
+		} else if placeholders {
+			if err := writeDotPlaceholder(w, opts, nodeId, "l"); err != nil {
+				return err
+			}
+		}
+
+		if n.Right != nil {
+			if _, err := fmt.Fprintf(w, rightFormat, nodeId, ids.get(n.Right)); err != nil {
+				return err
+			}
+		} else if placeholders {
+			if err := writeDotPlaceholder(w, opts, nodeId, "r"); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return n.WalkPreOrder(walkFunc)
+}
+
+// WriteDot generates the Dot representation of the binary tree using
+// the default options. Nodes are identified by sequential ids
+// assigned in the order in which they are encountered, so that the
+// output is reproducible.
+func (n *Node[T]) WriteDot(w io.Writer) error {
+	return n.WriteDotWithOptions(w, DotOptions{})
+}
+
+// WriteDotWithOptions generates the Dot representation of the binary
+// tree configured with the given options.
+func (n *Node[T]) WriteDotWithOptions(w io.Writer, opts DotOptions) error {
+	if err := writeDotHeader(w, opts); err != nil {
+		return err
+	}
+
+	if err := n.writeDotNodes(w, opts, defaultDotLabel[T], nodeIds[T]{}); err != nil {
+		return err
+	}
+
+	return writeDotFooter(w)
+}
+
+// WriteDotWithLabeler generates the Dot representation of the binary
+// tree, where the label of each node is generated by label. Special
+// characters in the labels, including the record field separators and
+// delimiters, are escaped.
+func (n *Node[T]) WriteDotWithLabeler(w io.Writer, label func(*Node[T]) string) error {
+	opts := DotOptions{}
+	if err := writeDotHeader(w, opts); err != nil {
+		return err
+	}
+
+	if err := n.writeDotNodes(w, opts, label, nodeIds[T]{}); err != nil {
+		return err
+	}
+
+	return writeDotFooter(w)
+}
+
+// WriteDotForest generates a single Dot representation containing
+// all of the given binary trees.
+func WriteDotForest[T any](w io.Writer, roots []*Node[T]) error {
+	opts := DotOptions{}
+	if err := writeDotHeader(w, opts); err != nil {
+		return err
+	}
+
+	ids := nodeIds[T]{}
+	for _, root := range roots {
+		if err := root.writeDotNodes(w, opts, defaultDotLabel[T], ids); err != nil {
+			return err
+		}
+	}
+
+	return writeDotFooter(w)
+}
+
+// prettyLine is a line of the ASCII representation of a tree.
+type prettyLine[T any] struct {
+	// node is the node to print, or nil for a missing child
+	node *Node[T]
+
+	// prefix is the indentation of the line
+	prefix string
+
+	// last specifies whether the node is the last child of its parent
+	last bool
+}
+
+// prettyReplacer sanitizes labels for use in PrettyPrint.
+var prettyReplacer = strings.NewReplacer("\r", "", "\n", `\n`)
+
+// PrettyPrint writes an ASCII representation of the binary tree, where
+// each node is printed on a separate line in pre-order, indented
+// below its parent. The left child is printed before the right one,
+// and a missing child is printed as <nil>, if its sibling is present.
+// E.g. the tree 1(2(4,5),3) is printed as follows.
+//
+//	1
+//	├── 2
+//	│   ├── 4
+//	│   └── 5
+//	└── 3
+func (n *Node[T]) PrettyPrint(w io.Writer) error {
+	child := func(node *Node[T]) *Node[T] {
+		if node == nil || n.shouldSkipNode(node) {
+			return nil
+		}
+		return node
+	}
+
+	if child(n) == nil {
+		return nil
+	}
+
+	stack := newRingDeque[*prettyLine[T]]()
+	stack.PushFront(&prettyLine[T]{node: n})
+
+	for !stack.IsEmpty() {
+		line, err := stack.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		// The root node is printed without a connector
+		connector, indent := "├── ", "│   "
+		switch {
+		case line.node == n:
+			connector, indent = "", ""
+		case line.last:
+			connector, indent = "└── ", "    "
+		}
+
+		if line.node == nil {
+			if _, err := fmt.Fprintf(w, "%s%s<nil>\n", line.prefix, connector); err != nil {
+				return err
+			}
+			continue
+		}
+
+		label := prettyReplacer.Replace(fmt.Sprintf("%v", line.node.Value))
+		if _, err := fmt.Fprintf(w, "%s%s%s\n", line.prefix, connector, label); err != nil {
+			return err
+		}
+
+		left, right := child(line.node.Left), child(line.node.Right)
+		if left == nil && right == nil {
+			continue
+		}
+
+		prefix := line.prefix + indent
+		stack.PushFront(&prettyLine[T]{node: right, prefix: prefix, last: true})
+		stack.PushFront(&prettyLine[T]{node: left, prefix: prefix})
+	}
+
+	return nil
+}
+
+// mermaidReplacer sanitizes labels for use in Mermaid.
+var mermaidReplacer = strings.NewReplacer(`"`, "#quot;", "\r", "", "\n", "<br>")
+
+// WriteMermaid generates the Mermaid flowchart representation of the
+// binary tree. The edges to the left children are emitted before the
+// edges to the right children, which keeps their positioning. Nodes
+// are identified by the order in which they are encountered, which
+// keeps the output stable.
+func (n *Node[T]) WriteMermaid(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "graph TD"); err != nil {
+		return err
+	}
+
+	ids := nodeIds[T]{}
+	walkFunc := func(node *Node[T]) error {
+		nodeId := ids.get(node)
+		label := mermaidReplacer.Replace(fmt.Sprintf("%v", node.Value))
+		if _, err := fmt.Fprintf(w, "\tn%d[\"%s\"]\n", nodeId, label); err != nil {
+			return err
+		}
+
+		for _, child := range []*Node[T]{node.Left, node.Right} {
+			if child == nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "\tn%d --> n%d\n", nodeId, ids.get(child)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return n.WalkPreOrder(walkFunc)
+}
+
+// plantUMLReplacer sanitizes labels for use in PlantUML.
+var plantUMLReplacer = strings.NewReplacer(`\`, `\\`, `"`, `'`, "\r", "", "\n", `\n`)
+
+// WritePlantUML generates the PlantUML object diagram representation
+// of the binary tree. Nodes are identified by the order in which they
+// are visited in pre-order, which keeps the output stable.
+func (n *Node[T]) WritePlantUML(w io.Writer) error {
+	ids := nodeIds[T]{}
+	nodeId := func(node *Node[T]) string {
+		return fmt.Sprintf("n%d", ids.get(node))
+	}
+
+	if _, err := fmt.Fprintln(w, "@startuml"); err != nil {
+		return err
+	}
+
+	walkFunc := func(node *Node[T]) error {
+		label := plantUMLReplacer.Replace(fmt.Sprintf("%v", node.Value))
+		id := nodeId(node)
+		if _, err := fmt.Fprintf(w, "object \"%s\" as %s\n", label, id); err != nil {
+			return err
+		}
+
+		if node.Left != nil {
+			if _, err := fmt.Fprintf(w, "%s --> %s : left\n", id, nodeId(node.Left)); err != nil {
+				return err
+			}
+		}
+
+		if node.Right != nil {
+			if _, err := fmt.Fprintf(w, "%s --> %s : right\n", id, nodeId(node.Right)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := n.WalkPreOrder(walkFunc); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintln(w, "@enduml")
+
+	return err
+}
+
+// FlipEquiv returns true, if tree a can be transformed into tree b by
+// swapping the left and right children of any number of nodes. The eq
+// function is used for comparing the values of the nodes.
+func FlipEquiv[T any](a, b *Node[T], eq func(x, y T) bool) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	if !eq(a.Value, b.Value) {
+		return false
+	}
+
+	sameOrder := FlipEquiv(a.Left, b.Left, eq) && FlipEquiv(a.Right, b.Right, eq)
+	if sameOrder {
+		return true
+	}
+
+	return FlipEquiv(a.Left, b.Right, eq) && FlipEquiv(a.Right, b.Left, eq)
+}
+
+// CountSubtreesWhere returns the number of subtrees, in which every
+// node satisfies the given predicate.
+func (n *Node[T]) CountSubtreesWhere(all FindFunc[T]) int {
+	// Post-order walking guarantees that children are visited
+	// before their parent, so we can decide on each subtree in a
+	// single bottom-up pass.
+	matches := make(map[*Node[T]]bool)
+	count := 0
+	walkFunc := func(node *Node[T]) error {
+		ok := all(node)
+		if node.Left != nil && !matches[node.Left] {
+			ok = false
+		}
+		if node.Right != nil && !matches[node.Right] {
+			ok = false
+		}
+
+		if ok {
+			matches[node] = true
+			count++
+		}
+
+		return nil
+	}
+
+	if err := n.WalkPostOrder(walkFunc); err != nil {
+		panic(err)
+	}
+
+	return count
+}
+
+// pathBounds tracks the minimum and maximum values seen on the path
+// from the root down to a given node.
+type pathBounds[T Number] struct {
+	node *Node[T]
+	min  T
+	max  T
+}
+
+// MaxAncestorDescendantDiff returns the maximum absolute difference
+// between the values of a node and any of its descendants.
+func MaxAncestorDescendantDiff[T Number](root *Node[T]) T {
+	var result T
+	if root == nil {
+		return result
+	}
+
+	stack := newRingDeque[*pathBounds[T]]()
+	stack.PushFront(&pathBounds[T]{node: root, min: root.Value, max: root.Value})
+
+	for !stack.IsEmpty() {
+		item, err := stack.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		lo, hi := item.min, item.max
+		if item.node.Value < lo {
+			lo = item.node.Value
+		}
+		if item.node.Value > hi {
+			hi = item.node.Value
+		}
+
+		if hi-lo > result {
+			result = hi - lo
+		}
+
+		if item.node.Right != nil {
+			stack.PushFront(&pathBounds[T]{node: item.node.Right, min: lo, max: hi})
+		}
+		if item.node.Left != nil {
+			stack.PushFront(&pathBounds[T]{node: item.node.Left, min: lo, max: hi})
+		}
+	}
+
+	return result
+}
+
+// WeightedDepthSum returns the sum of each node's value multiplied by
+// the depth of the node, where the root node is at depth 0.
+func WeightedDepthSum[T Number](root *Node[T]) T {
+	var sum T
+	if root == nil {
+		return sum
+	}
+
+	stack := newRingDeque[*nodeHeight[T]]()
+	stack.PushFront(&nodeHeight[T]{node: root, height: 0})
+
+	for !stack.IsEmpty() {
+		item, err := stack.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		sum += item.node.Value * T(item.height)
+
+		if item.node.Right != nil {
+			stack.PushFront(&nodeHeight[T]{node: item.node.Right, height: item.height + 1})
+		}
+		if item.node.Left != nil {
+			stack.PushFront(&nodeHeight[T]{node: item.node.Left, height: item.height + 1})
+		}
+	}
+
+	return sum
+}
+
+// pathSum tracks the sum of the values on the path from the root down
+// to a given node, including the node.
+type pathSum[T Number] struct {
+	node *Node[T]
+	sum  T
+}
+
+// HasPathSum returns true, if the values on any path from the root
+// down to a leaf node sum up to the target. Partial paths, which do
+// not end at a leaf node, are not considered. Sums of floating-point
+// values are compared exactly.
+func HasPathSum[T Number](root *Node[T], target T) bool {
+	if root == nil {
+		return false
+	}
+
+	stack := newRingDeque[*pathSum[T]]()
+	stack.PushFront(&pathSum[T]{node: root, sum: root.Value})
+
+	for !stack.IsEmpty() {
+		item, err := stack.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		if item.node.IsLeafNode() && item.sum == target {
+			return true
+		}
+
+		if item.node.Right != nil {
+			stack.PushFront(&pathSum[T]{node: item.node.Right, sum: item.sum + item.node.Right.Value})
+		}
+		if item.node.Left != nil {
+			stack.PushFront(&pathSum[T]{node: item.node.Left, sum: item.sum + item.node.Left.Value})
+		}
+	}
+
+	return false
+}
+
+// nodePair is a pair of nodes from two trees, which are compared
+// against each other.
+type nodePair[T any] struct {
+	a *Node[T]
+	b *Node[T]
+}
+
+// Equal returns true, if both trees have identical shape and the
+// values of the corresponding nodes are equal according to cmp.
+func (n *Node[T]) Equal(other *Node[T], cmp func(a, b T) bool) bool {
+	if n == nil || other == nil {
+		return n == nil && other == nil
+	}
+
+	return n.matches(other, cmp, false)
+}
+
+// matches returns true, if the tree rooted at the node matches the
+// other tree, i.e. the corresponding nodes are equal according to cmp.
+// When prefix is true, the nodes may have children, which are missing
+// in the other tree, otherwise both trees must have identical shape.
+func (n *Node[T]) matches(other *Node[T], cmp func(a, b T) bool, prefix bool) bool {
+	stack := newRingDeque[*nodePair[T]]()
+	stack.PushFront(&nodePair[T]{a: n, b: other})
+
+	for !stack.IsEmpty() {
+		pair, err := stack.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		if !cmp(pair.a.Value, pair.b.Value) {
+			return false
+		}
+
+		if prefix {
+			if (pair.b.Left != nil && pair.a.Left == nil) || (pair.b.Right != nil && pair.a.Right == nil) {
+				return false
+			}
+		} else if (pair.a.Left == nil) != (pair.b.Left == nil) || (pair.a.Right == nil) != (pair.b.Right == nil) {
+			return false
+		}
+
+		if pair.b.Right != nil {
+			stack.PushFront(&nodePair[T]{a: pair.a.Right, b: pair.b.Right})
+		}
+		if pair.b.Left != nil {
+			stack.PushFront(&nodePair[T]{a: pair.a.Left, b: pair.b.Left})
+		}
+	}
+
+	return true
+}
+
+// ContainsSubtree returns true, if some node in the tree rooted at the
+// node begins a match of sub, i.e. the node and its descendants have
+// the shape of sub, and their values are equal to the corresponding
+// values of sub according to cmp. The matching node may have
+// descendants, which are missing in sub, so that a single node sub
+// matches any node with an equal value. An empty sub is contained in
+// any tree.
+func (n *Node[T]) ContainsSubtree(sub *Node[T], cmp func(a, b T) bool) bool {
+	if sub == nil {
+		return true
+	}
+
+	predicate := func(node *Node[T]) bool {
+		return node.matches(sub, cmp, true)
+	}
+	_, ok := n.FindNode(predicate)
+
+	return ok
+}
+
+// Equal returns true, if both trees have identical shape and the
+// values of the corresponding nodes are equal.
+func Equal[T comparable](a, b *Node[T]) bool {
+	cmp := func(x, y T) bool {
+		return x == y
+	}
+
+	return a.Equal(b, cmp)
+}
+
+// ErrRoundTripMismatch is returned by VerifyRoundTrip when the
+// unmarshaled tree does not match the original one.
+var ErrRoundTripMismatch = errors.New("round-trip tree mismatch")
+
+// VerifyRoundTrip marshals the tree using the given marshal function,
+// unmarshals the result back using the unmarshal function and
+// verifies that the resulting tree matches the original one. The eq
+// function is used for comparing the values of the nodes.
+func (n *Node[T]) VerifyRoundTrip(marshal func(*Node[T]) ([]byte, error), unmarshal func([]byte) (*Node[T], error), eq func(a, b T) bool) error {
+	data, err := marshal(n)
+	if err != nil {
+		return fmt.Errorf("unable to marshal tree: %w", err)
+	}
+
+	tree, err := unmarshal(data)
+	if err != nil {
+		return fmt.Errorf("unable to unmarshal tree: %w", err)
+	}
+
+	if !n.Equal(tree, eq) {
+		return fmt.Errorf("%w: %s", ErrRoundTripMismatch, data)
+	}
+
+	return nil
+}
+
+// NodesAtDistance returns the nodes, which are exactly k edges away
+// from the target node. Both the descendants of the target node and
+// the nodes reachable through its ancestors within the tree rooted at
+// n are considered.
+func (n *Node[T]) NodesAtDistance(target *Node[T], k int) []*Node[T] {
+	result := make([]*Node[T], 0)
+	if k < 0 {
+		return result
+	}
+
+	// Map each node to its parent, so that we can move upwards
+	// from the target node.
+	parents := make(map[*Node[T]]*Node[T])
+	found := false
+	queue := newRingDeque[*Node[T]]()
+	queue.PushBack(n)
+
+	for !queue.IsEmpty() {
+		node, err := queue.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		if node == target {
+			found = true
+		}
+
+		if node.Left != nil {
+			parents[node.Left] = node
+			queue.PushBack(node.Left)
+		}
+		if node.Right != nil {
+			parents[node.Right] = node
+			queue.PushBack(node.Right)
+		}
+	}
+
+	if !found {
+		return result
+	}
+
+	// Breadth-first search from the target node in all directions
+	visited := map[*Node[T]]bool{target: true}
+	frontier := []*Node[T]{target}
+	for distance := 0; distance < k && len(frontier) > 0; distance++ {
+		next := make([]*Node[T], 0)
+		for _, node := range frontier {
+			for _, neighbour := range []*Node[T]{node.Left, node.Right, parents[node]} {
+				if neighbour != nil && !visited[neighbour] {
+					visited[neighbour] = true
+					next = append(next, neighbour)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return append(result, frontier...)
+}
+
+// leaves returns the leaf nodes of the tree from left to right.
+func (n *Node[T]) leaves() []*Node[T] {
+	result := make([]*Node[T], 0)
+	walkFunc := func(node *Node[T]) error {
+		if node.IsLeafNode() {
+			result = append(result, node)
+		}
+		return nil
+	}
+
+	if err := n.WalkPreOrder(walkFunc); err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+// LeafSimilar returns true, if the left-to-right sequence of leaf
+// values is the same for both trees, regardless of their internal
+// structure. The eq function is used for comparing the values.
+func LeafSimilar[T any](a, b *Node[T], eq func(x, y T) bool) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	aLeaves := a.leaves()
+	bLeaves := b.leaves()
+	if len(aLeaves) != len(bLeaves) {
+		return false
+	}
+
+	for i := range aLeaves {
+		if !eq(aLeaves[i].Value, bLeaves[i].Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LongestIncreasingPath returns the number of nodes on the longest
+// downward path, along which the value of each child is strictly
+// greater than the value of its parent according to less.
+func LongestIncreasingPath[T any](root *Node[T], less func(a, b T) bool) int {
+	if root == nil {
+		return 0
+	}
+
+	// The height of each item holds the number of nodes in the
+	// increasing path, which ends at the item's node.
+	longest := 0
+	stack := newRingDeque[*nodeHeight[T]]()
+	stack.PushFront(&nodeHeight[T]{node: root, height: 1})
+
+	for !stack.IsEmpty() {
+		item, err := stack.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		if item.height > longest {
+			longest = item.height
+		}
+
+		for _, child := range []*Node[T]{item.node.Right, item.node.Left} {
+			if child == nil {
+				continue
+			}
+
+			length := 1
+			if less(item.node.Value, child.Value) {
+				length = item.height + 1
+			}
+			stack.PushFront(&nodeHeight[T]{node: child, height: length})
+		}
+	}
+
+	return longest
+}
+
+// cloneNode returns a copy of the node without its children. The
+// attributes and skip node handlers of the node are copied as well,
+// while the metadata is shared with the original node.
+func (n *Node[T]) cloneNode() *Node[T] {
+	node := NewNode(n.Value)
+	node.meta = n.meta
+	node.height, node.heightCached = n.height, n.heightCached
+	node.size, node.sizeCached = n.size, n.sizeCached
+	node.skipNodeFuncs = append(node.skipNodeFuncs, n.skipNodeFuncs...)
+	for k, v := range n.dotAttributes {
+		node.dotAttributes[k] = v
+	}
+
+	return node
+}
+
+// Clone returns a deep copy of the tree rooted at the node, including
+// the attributes and skip node handlers of each node. The tree is
+// copied iteratively, so that cloning pathologically deep trees does
+// not exhaust the call stack.
+func (n *Node[T]) Clone() *Node[T] {
+	root := n.cloneNode()
+	stack := newRingDeque[*nodePair[T]]()
+	stack.PushFront(&nodePair[T]{a: n, b: root})
+
+	for !stack.IsEmpty() {
+		pair, err := stack.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		if pair.a.Right != nil {
+			right := pair.a.Right.cloneNode()
+			right.parent = pair.b
+			pair.b.Right = right
+			stack.PushFront(&nodePair[T]{a: pair.a.Right, b: right})
+		}
+		if pair.a.Left != nil {
+			left := pair.a.Left.cloneNode()
+			left.parent = pair.b
+			pair.b.Left = left
+			stack.PushFront(&nodePair[T]{a: pair.a.Left, b: left})
+		}
+	}
+
+	return root
+}
+
+// updateBounds recomputes the cached bounds of the node from the
+// cached bounds of its children.
+func (n *Node[T]) updateBounds(cmp ComparatorFunc[T]) {
+	n.subtreeMin, n.subtreeMax = n, n
+	for _, child := range []*Node[T]{n.Left, n.Right} {
+		if child == nil || child.subtreeMin == nil {
+			continue
+		}
+
+		if cmp(child.subtreeMin.Value, n.subtreeMin.Value) < 0 {
+			n.subtreeMin = child.subtreeMin
+		}
+		if cmp(child.subtreeMax.Value, n.subtreeMax.Value) > 0 {
+			n.subtreeMax = child.subtreeMax
+		}
+	}
+}
+
+// RecomputeBounds recomputes the cached minimum and maximum values of
+// each sub-tree within the tree rooted at the node. It should be
+// called after the tree has been mutated directly via the Left/Right
+// fields, or after the bounds have been invalidated, e.g. by
+// InsertLeft/InsertRight or Remove, in order to repair the cache.
+func (n *Node[T]) RecomputeBounds(cmp ComparatorFunc[T]) {
+	walkFunc := func(node *Node[T]) error {
+		node.updateBounds(cmp)
+		return nil
+	}
+
+	if err := n.walkPostOrder(noSkipNode[T], walkFunc); err != nil {
+		panic(err)
+	}
+}
+
+// Bounds returns the cached minimum and maximum values of the
+// sub-tree rooted at the node. The bool result is false, if the
+// bounds have not been computed yet, or have been invalidated.
+func (n *Node[T]) Bounds() (T, T, bool) {
+	var empty T
+	if n.subtreeMin == nil || n.subtreeMax == nil {
+		return empty, empty, false
+	}
+
+	return n.subtreeMin.Value, n.subtreeMax.Value, true
+}
+
+// InRange returns true, if all values in the sub-tree rooted at the
+// node are within the range [lo, hi]. The check is O(1) when the
+// bounds of the node are cached, otherwise the bounds are computed
+// first.
+func (n *Node[T]) InRange(lo, hi T, cmp ComparatorFunc[T]) bool {
+	if n.subtreeMin == nil {
+		n.RecomputeBounds(cmp)
+	}
+
+	return cmp(lo, n.subtreeMin.Value) <= 0 && cmp(n.subtreeMax.Value, hi) <= 0
+}
+
+// Diameter returns the number of edges on the longest path between
+// any two nodes of the tree. The heights of the sub-trees are computed
+// in a single post-order pass.
+func (n *Node[T]) Diameter() int {
+	heights := make(map[*Node[T]]int)
+	height := func(node *Node[T]) int {
+		if node == nil {
+			return -1
+		}
+		return heights[node]
+	}
+
+	diameter := 0
+	walkFunc := func(node *Node[T]) error {
+		left, right := height(node.Left), height(node.Right)
+		heights[node] = 1 + left
+		if right > left {
+			heights[node] = 1 + right
+		}
+
+		if edges := left + right + 2; edges > diameter {
+			diameter = edges
+		}
+
+		return nil
+	}
+
+	if err := n.walkPostOrder(noSkipNode[T], walkFunc); err != nil {
+		panic(err)
+	}
+
+	return diameter
+}
+
+// DiameterPath returns the nodes on a longest path between any two
+// nodes of the tree. When multiple longest paths exist, the left-most
+// one is returned.
+func (n *Node[T]) DiameterPath() []*Node[T] {
+	// heights maps each node to its height, and deepest maps each
+	// node to the child leading to its deepest descendant.
+	heights := make(map[*Node[T]]int)
+	deepest := make(map[*Node[T]]*Node[T])
+	height := func(node *Node[T]) int {
+		if node == nil {
+			return -1
+		}
+		return heights[node]
+	}
+
+	var top *Node[T]
+	longest := -1
+	walkFunc := func(node *Node[T]) error {
+		left, right := height(node.Left), height(node.Right)
+		if left >= right {
+			heights[node] = left + 1
+			deepest[node] = node.Left
+		} else {
+			heights[node] = right + 1
+			deepest[node] = node.Right
+		}
+
+		if edges := left + right + 2; edges > longest {
+			longest = edges
+			top = node
+		}
+
+		return nil
+	}
+
+	if err := n.walkPostOrder(noSkipNode[T], walkFunc); err != nil {
+		panic(err)
+	}
+
+	// The path goes up from the deepest node of the left sub-tree
+	// to the top node and then down to the deepest node of the
+	// right sub-tree.
+	path := make([]*Node[T], 0, longest+1)
+	for node := top.Left; node != nil; node = deepest[node] {
+		path = append(path, node)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	path = append(path, top)
+	for node := top.Right; node != nil; node = deepest[node] {
+		path = append(path, node)
+	}
+
+	return path
+}
+
+// StructureKey returns a canonical string representation of the tree,
+// which combines its structure and the keys of the node values as
+// returned by valueKey. Trees with identical shape and value keys
+// produce the same string, which makes it suitable for use as a map
+// key.
+func (n *Node[T]) StructureKey(valueKey func(T) string) string {
+	// The key is the pre-order sequence of the quoted value keys,
+	// where missing children are denoted by "#".
+	tokens := make([]string, 0)
+	stack := newRingDeque[*Node[T]]()
+	stack.PushFront(n)
+
+	for !stack.IsEmpty() {
+		node, err := stack.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		if node == nil {
+			tokens = append(tokens, "#")
+			continue
+		}
+
+		tokens = append(tokens, strconv.Quote(valueKey(node.Value)))
+		stack.PushFront(node.Right)
+		stack.PushFront(node.Left)
+	}
+
+	return strings.Join(tokens, ",")
+}
+
+// Invert mirrors the tree rooted at the node in place by swapping the
+// left and right children of every node.
+func (n *Node[T]) Invert() {
+	stack := newRingDeque[*Node[T]]()
+	stack.PushFront(n)
+
+	for !stack.IsEmpty() {
+		node, err := stack.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		node.Left, node.Right = node.Right, node.Left
+
+		if node.Right != nil {
+			stack.PushFront(node.Right)
+		}
+		if node.Left != nil {
+			stack.PushFront(node.Left)
+		}
+	}
+}
+
+// Mirrored returns a new tree, which is the mirror image of the tree
+// rooted at the node. The original tree is not modified.
+func (n *Node[T]) Mirrored() *Node[T] {
+	clone := n.Clone()
+	clone.Invert()
+
+	return clone
+}
+
+// IsSymmetric returns true, if the tree is a mirror image of itself
+// around the root node, i.e. the left and right sub-trees of the root
+// node are structural mirrors with values which are equal according
+// to cmp.
+func (n *Node[T]) IsSymmetric(cmp func(a, b T) bool) bool {
+	queue := newRingDeque[*nodePair[T]]()
+	queue.PushBack(&nodePair[T]{a: n.Left, b: n.Right})
+
+	for !queue.IsEmpty() {
+		pair, err := queue.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		if pair.a == nil || pair.b == nil {
+			if pair.a != pair.b {
+				return false
+			}
+			continue
+		}
+
+		if !cmp(pair.a.Value, pair.b.Value) {
+			return false
+		}
+
+		queue.PushBack(&nodePair[T]{a: pair.a.Left, b: pair.b.Right})
+		queue.PushBack(&nodePair[T]{a: pair.a.Right, b: pair.b.Left})
+	}
+
+	return true
+}
+
+// SearchBST searches for the given value in a Binary Search Tree
+// (BST) by descending into the left or right sub-tree depending on
+// the comparator result. It returns the first node on the search
+// path, whose value compares equal to the given value.
+func (n *Node[T]) SearchBST(value T, cmp ComparatorFunc[T]) (*Node[T], bool) {
+	node := n
+	for node != nil {
+		switch result := cmp(value, node.Value); {
+		case result == 0:
+			return node, true
+		case result < 0:
+			node = node.Left
+		default:
+			node = node.Right
+		}
+	}
+
+	return nil, false
+}
+
+// DeleteBST deletes the first node holding the given value from a
+// Binary Search Tree (BST) rooted at the node, and returns the new
+// root of the tree along with whether a node was deleted. A node with
+// two children is not detached itself, instead its value is replaced
+// with the value of its in-order successor, which is deleted in turn.
+// When the root node is deleted, the caller should use the returned
+// node as the new root. If the bounds of the tree are cached, they
+// are updated along the deletion path.
+func (n *Node[T]) DeleteBST(value T, cmp ComparatorFunc[T]) (*Node[T], bool) {
+	path, ok := n.SearchPathBST(value, cmp)
+	if !ok {
+		return n, false
+	}
+
+	target := path[len(path)-1]
+	path = path[:len(path)-1]
+	if target.Left != nil && target.Right != nil {
+		path = append(path, target)
+		successor := target.Right
+		for successor.Left != nil {
+			path = append(path, successor)
+			successor = successor.Left
+		}
+		target.Value = successor.Value
+		target = successor
+	}
+
+	child := target.Left
+	if child == nil {
+		child = target.Right
+	}
+
+	// When the node itself is deleted and it is the root of a
+	// sub-tree, the child link of its parent is updated as well.
+	root := n
+	outer := n.parent
+	parent := outer
+	if len(path) == 0 {
+		root = child
+	} else {
+		parent = path[len(path)-1]
+	}
+
+	parent.replaceChild(target, child)
+	target.parent, target.Left, target.Right = nil, nil, nil
+	if parent != nil {
+		parent.updateCaches()
+	}
+
+	if n.subtreeMin != nil {
+		for i := len(path) - 1; i >= 0; i-- {
+			path[i].updateBounds(cmp)
+		}
+		for node := outer; node != nil && node.subtreeMin != nil; node = node.parent {
+			node.updateBounds(cmp)
+		}
+	}
+
+	return root, true
+}
+
+// Min returns the left-most node of the tree rooted at the node. The
+// result holds the minimum value only if the tree is a valid Binary
+// Search Tree (BST).
+func (n *Node[T]) Min() *Node[T] {
+	node := n
+	for node.Left != nil {
+		node = node.Left
+	}
+
+	return node
+}
+
+// Max returns the right-most node of the tree rooted at the node. The
+// result holds the maximum value only if the tree is a valid Binary
+// Search Tree (BST).
+func (n *Node[T]) Max() *Node[T] {
+	node := n
+	for node.Right != nil {
+		node = node.Right
+	}
+
+	return node
+}
+
+// SearchPathBST searches for the given value in a Binary Search Tree
+// (BST) and returns the sequence of nodes visited during the search.
+// The bool result reports whether the value was found, in which case
+// the last node of the path holds the value.
+func (n *Node[T]) SearchPathBST(value T, cmp ComparatorFunc[T]) ([]*Node[T], bool) {
+	path := make([]*Node[T], 0)
+	node := n
+
+	for node != nil {
+		path = append(path, node)
+		switch result := cmp(value, node.Value); {
+		case result == 0:
+			return path, true
+		case result < 0:
+			node = node.Left
+		default:
+			node = node.Right
+		}
+	}
+
+	return path, false
+}
+
+// cameraState is the state of a node while placing cameras.
+type cameraState int
+
+const (
+	// notCovered means the node is not monitored yet
+	notCovered cameraState = iota
+	// hasCamera means a camera is placed at the node
+	hasCamera
+	// covered means the node is monitored by a neighbour camera
+	covered
+)
+
+// MinCameras returns the minimum number of cameras needed to monitor
+// every node of the tree, where a camera placed at a node monitors
+// the node itself, its parent and its children.
+func (n *Node[T]) MinCameras() int {
+	// Cameras are placed greedily bottom-up at the parents of
+	// nodes, which are not covered yet.
+	states := make(map[*Node[T]]cameraState)
+	state := func(node *Node[T]) cameraState {
+		if node == nil {
+			return covered
+		}
+		return states[node]
+	}
+
+	cameras := 0
+	walkFunc := func(node *Node[T]) error {
+		left, right := state(node.Left), state(node.Right)
+		switch {
+		case left == notCovered || right == notCovered:
+			states[node] = hasCamera
+			cameras++
+		case left == hasCamera || right == hasCamera:
+			states[node] = covered
+		default:
+			states[node] = notCovered
+		}
+
+		return nil
+	}
+
+	if err := n.walkPostOrder(noSkipNode[T], walkFunc); err != nil {
+		panic(err)
+	}
+
+	if states[n] == notCovered {
+		cameras++
+	}
+
+	return cameras
+}
+
+// Depth returns the depth of the target node relative to the node,
+// i.e. the number of edges between them. The bool result is false, if
+// the target node is not part of the sub-tree rooted at the node.
+func (n *Node[T]) Depth(target *Node[T]) (int, bool) {
+	queue := newRingDeque[*nodeHeight[T]]()
+	queue.PushBack(&nodeHeight[T]{node: n, height: 0})
+
+	for !queue.IsEmpty() {
+		item, err := queue.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		if item.node == target {
+			return item.height, true
+		}
+
+		if item.node.Left != nil {
+			queue.PushBack(&nodeHeight[T]{node: item.node.Left, height: item.height + 1})
+		}
+		if item.node.Right != nil {
+			queue.PushBack(&nodeHeight[T]{node: item.node.Right, height: item.height + 1})
+		}
+	}
+
+	return 0, false
+}
+
+// Subtrees returns the root nodes of all sub-trees, whose root node
+// satisfies the given predicate, in pre-order. The returned nodes are
+// live pointers into the original tree, so any changes to them are
+// reflected in the original tree as well.
+func (n *Node[T]) Subtrees(rootPred FindFunc[T]) []*Node[T] {
+	return n.FindAll(rootPred)
+}
+
+// Prune removes each sub-tree whose root satisfies the given
+// predicate, and returns the number of removed nodes. The node itself
+// is never removed, even if it satisfies the predicate, and only its
+// descendants are examined. Unlike the skip node handlers, which only
+// affect the traversals, the pruned sub-trees are detached from the
+// tree, and the cached heights and sizes are updated.
+func (n *Node[T]) Prune(shouldRemove FindFunc[T]) int {
+	// The matching children are detached through the node being
+	// visited, before the walker descends into them, so that
+	// children linked directly via the Left/Right fields are pruned
+	// as well.
+	removed := 0
+	walkFunc := func(node *Node[T]) error {
+		for _, link := range []**Node[T]{&node.Left, &node.Right} {
+			if *link != nil && shouldRemove(*link) {
+				removed += countAll(*link)
+				node.attach(link, nil)
+			}
+		}
+		return nil
+	}
+
+	if err := n.walkPreOrder(noSkipNode[T], walkFunc); err != nil {
+		panic(err)
+	}
+
+	return removed
+}
+
+// ToSlice returns the values of the nodes visited in the given order.
+// ToSlice panics, if the traversal order is invalid.
+func (n *Node[T]) ToSlice(order TraversalOrder) []T {
+	result := make([]T, 0)
+	walkFunc := func(node *Node[T]) error {
+		result = append(result, node.Value)
+		return nil
+	}
+
+	if err := n.walk(order, n.shouldSkipNode, walkFunc); err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+// errSequenceMismatch is returned by a walking function when the
+// sequence of visited nodes differs from the expected one.
+var errSequenceMismatch = errors.New("sequence mismatch")
+
+// SequenceEqual returns true, if the values of the nodes visited in
+// the given order match the wanted values according to eq.
+func (n *Node[T]) SequenceEqual(order TraversalOrder, want []T, eq func(a, b T) bool) bool {
+	i := 0
+	walkFunc := func(node *Node[T]) error {
+		if i >= len(want) || !eq(node.Value, want[i]) {
+			return errSequenceMismatch
+		}
+		i++
+
+		return nil
+	}
+
+	err := n.walk(order, n.shouldSkipNode, walkFunc)
+	switch {
+	case err == errSequenceMismatch || err == ErrInvalidTraversalOrder:
+		return false
+	case err != nil:
+		panic(err)
+	default:
+		return i == len(want)
+	}
+}
+
+// AppendComplete inserts a new node with the given value at the next
+// available position in level-order and returns the new node. When
+// called on a complete tree, the tree remains complete.
+func (n *Node[T]) AppendComplete(value T) *Node[T] {
+	queue := newRingDeque[*Node[T]]()
+	queue.PushBack(n)
+
+	for {
+		node, err := queue.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		if node.Left == nil {
+			return node.InsertLeft(value)
+		}
+		if node.Right == nil {
+			return node.InsertRight(value)
+		}
+
+		queue.PushBack(node.Left)
+		queue.PushBack(node.Right)
+	}
+}
+
+// Map returns a new tree with the same shape as the tree rooted at
+// root, where the value of each node is the result of applying fn to
+// the value of the corresponding node. Attributes and skip node
+// handlers are not carried over to the new tree.
+func Map[T, U any](root *Node[T], fn func(T) U) *Node[U] {
+	if root == nil {
+		return nil
+	}
+
+	type mapping struct {
+		from *Node[T]
+		to   *Node[U]
+	}
+
+	result := NewNode(fn(root.Value))
+	stack := newRingDeque[*mapping]()
+	stack.PushFront(&mapping{from: root, to: result})
+
+	for !stack.IsEmpty() {
+		item, err := stack.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		if item.from.Right != nil {
+			right := item.to.InsertRight(fn(item.from.Right.Value))
+			stack.PushFront(&mapping{from: item.from.Right, to: right})
+		}
+		if item.from.Left != nil {
+			left := item.to.InsertLeft(fn(item.from.Left.Value))
+			stack.PushFront(&mapping{from: item.from.Left, to: left})
+		}
+	}
+
+	return result
+}
+
+// RemoveLastComplete detaches the last node in level-order from the
+// tree and returns it. When called on a complete tree, the tree
+// remains complete. The bool result is false, if the tree consists of
+// the root node only, since the root cannot detach itself.
+func (n *Node[T]) RemoveLastComplete() (*Node[T], bool) {
+	// Each pair holds a node and its parent
+	var last *nodePair[T]
+	queue := newRingDeque[*nodePair[T]]()
+	queue.PushBack(&nodePair[T]{a: n})
+
+	for !queue.IsEmpty() {
+		item, err := queue.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		last = item
+		if item.a.Left != nil {
+			queue.PushBack(&nodePair[T]{a: item.a.Left, b: item.a})
+		}
+		if item.a.Right != nil {
+			queue.PushBack(&nodePair[T]{a: item.a.Right, b: item.a})
+		}
+	}
+
+	node, parent := last.a, last.b
+	if parent == nil {
+		return nil, false
+	}
+
+	if parent.Right == node {
+		parent.Right = nil
+	} else {
+		parent.Left = nil
+	}
+	node.parent = nil
+	parent.updateCaches()
+	parent.invalidateBounds()
+
+	return node, true
+}
+
+// Fold reduces the values of the tree to a single value, by invoking
+// fn with the accumulated value and the value of each node visited in
+// the given order, starting with init. Fold panics, if the traversal
+// order is invalid.
+func Fold[T, A any](root *Node[T], init A, fn func(acc A, value T) A, order TraversalOrder) A {
+	acc := init
+	if root == nil {
+		return acc
+	}
+
+	walkFunc := func(node *Node[T]) error {
+		acc = fn(acc, node.Value)
+		return nil
+	}
+
+	if err := root.walk(order, root.shouldSkipNode, walkFunc); err != nil {
+		panic(err)
+	}
+
+	return acc
+}
+
+// Sum returns the sum of the values of the tree. The sum of integer
+// values wraps around on overflow, as with the regular Go arithmetic,
+// so callers summing large values should use a wider type. Sum returns
+// 0, if root is nil.
+func Sum[T Number](root *Node[T]) T {
+	add := func(acc T, value T) T {
+		return acc + value
+	}
+
+	return Fold(root, T(0), add, PreOrder)
+}
+
+// Stats returns the minimum and maximum values of the tree according
+// to less, along with the number of nodes, computed in a single
+// traversal. The skip node handlers of root are honored. Stats
+// returns zero values and a count of 0, if root is nil, or if no node
+// is visited.
+func Stats[T any](root *Node[T], less func(a, b T) bool) (min, max T, count int) {
+	if root == nil {
+		return min, max, 0
+	}
+
+	walkFunc := func(node *Node[T]) error {
+		if count == 0 {
+			min, max = node.Value, node.Value
+		}
+		if less(node.Value, min) {
+			min = node.Value
+		}
+		if less(max, node.Value) {
+			max = node.Value
+		}
+		count++
+		return nil
+	}
+
+	if err := root.WalkPreOrder(walkFunc); err != nil {
+		panic(err)
+	}
+
+	return min, max, count
+}
+
+// mergeItem is a node of a merged tree along with the nodes from the
+// source trees, which are merged into it.
+type mergeItem[T any] struct {
+	node *Node[T]
+	a    *Node[T]
+	b    *Node[T]
+}
+
+// Merge returns a new tree, which overlays the given trees. Where both
+// trees have a node at the same position, the value of the new node is
+// the result of combine, otherwise the value of the present node is
+// used as-is. The given trees are not modified. Merge returns nil, if
+// both trees are empty.
+func Merge[T any](a, b *Node[T], combine func(x, y T) T) *Node[T] {
+	// merged returns the value of the node merged from the given
+	// nodes, at least one of which is present
+	merged := func(x, y *Node[T]) T {
+		switch {
+		case x == nil:
+			return y.Value
+		case y == nil:
+			return x.Value
+		default:
+			return combine(x.Value, y.Value)
+		}
+	}
+
+	// children returns the children of the given node, if present
+	children := func(node *Node[T]) (*Node[T], *Node[T]) {
+		if node == nil {
+			return nil, nil
+		}
+		return node.Left, node.Right
+	}
+
+	if a == nil && b == nil {
+		return nil
+	}
+
+	root := NewNode(merged(a, b))
+	stack := newRingDeque[*mergeItem[T]]()
+	stack.PushFront(&mergeItem[T]{node: root, a: a, b: b})
+
+	for !stack.IsEmpty() {
+		item, err := stack.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		aLeft, aRight := children(item.a)
+		bLeft, bRight := children(item.b)
+
+		if aRight != nil || bRight != nil {
+			right := item.node.InsertRight(merged(aRight, bRight))
+			stack.PushFront(&mergeItem[T]{node: right, a: aRight, b: bRight})
+		}
+		if aLeft != nil || bLeft != nil {
+			left := item.node.InsertLeft(merged(aLeft, bLeft))
+			stack.PushFront(&mergeItem[T]{node: left, a: aLeft, b: bLeft})
+		}
+	}
+
+	return root
+}
+
+// nodeIndex is a node along with its position index within a level.
+type nodeIndex[T any] struct {
+	node  *Node[T]
+	index int
+}
+
+// WidthSpans returns the width of each level of the tree, where the
+// width of a level is the number of positions between its left-most
+// and right-most nodes, including the missing nodes in between.
+//
+// The position indices are normalized on each level, so that
+// computing the spans of very deep trees does not overflow.
+func (n *Node[T]) WidthSpans() []int {
+	spans := make([]int, 0)
+	queue := newRingDeque[*nodeIndex[T]]()
+	queue.PushBack(&nodeIndex[T]{node: n, index: 0})
+
+	for !queue.IsEmpty() {
+		first, err := queue.PeekFront()
+		if err != nil {
+			panic(err)
+		}
+
+		// Indices of the level are relative to the left-most node
+		offset := first.index
+		last := 0
+		for size := queue.Length(); size > 0; size-- {
+			item, err := queue.PopFront()
+			if err != nil {
+				panic(err)
+			}
+
+			index := item.index - offset
+			last = index
+			if item.node.Left != nil && !n.shouldSkipNode(item.node.Left) {
+				queue.PushBack(&nodeIndex[T]{node: item.node.Left, index: 2 * index})
+			}
+			if item.node.Right != nil && !n.shouldSkipNode(item.node.Right) {
+				queue.PushBack(&nodeIndex[T]{node: item.node.Right, index: 2*index + 1})
+			}
+		}
+
+		spans = append(spans, last+1)
+	}
+
+	return spans
+}
+
+// Width returns the maximum width among all levels of the tree, as
+// computed by WidthSpans.
+func (n *Node[T]) Width() int {
+	width := 0
+	for _, span := range n.WidthSpans() {
+		if span > width {
+			width = span
+		}
+	}
+
+	return width
+}
+
+// MaxWidth returns the maximum number of nodes present on any single
+// level of the tree.
+func (n *Node[T]) MaxWidth() int {
+	maxWidth, width := 0, 0
+	nodeFunc := func(node *Node[T]) error {
+		width++
+		return nil
+	}
+	endOfLevel := func(level int) error {
+		if width > maxWidth {
+			maxWidth = width
+		}
+		width = 0
+		return nil
+	}
+
+	if err := n.WalkLevelOrderWithMarker(nodeFunc, endOfLevel); err != nil {
+		panic(err)
+	}
+
+	return maxWidth
+}
+
+// GroupBy returns the values of the tree grouped by the result of
+// applying key to each value. The values within each group are in
+// pre-order.
+func GroupBy[T any, K comparable](root *Node[T], key func(T) K) map[K][]T {
+	groups := make(map[K][]T)
+	if root == nil {
+		return groups
+	}
+
+	walkFunc := func(node *Node[T]) error {
+		k := key(node.Value)
+		groups[k] = append(groups[k], node.Value)
+		return nil
+	}
+
+	if err := root.WalkPreOrder(walkFunc); err != nil {
+		panic(err)
+	}
+
+	return groups
+}
+
+// Levels returns the nodes of the tree grouped by level, where the
+// i-th element holds the nodes at depth i in left-to-right order.
+func (n *Node[T]) Levels() [][]*Node[T] {
+	levels := make([][]*Node[T], 0)
+	level := make([]*Node[T], 0)
+	nodeFunc := func(node *Node[T]) error {
+		level = append(level, node)
+		return nil
+	}
+	endOfLevel := func(depth int) error {
+		levels = append(levels, level)
+		level = make([]*Node[T], 0)
+		return nil
+	}
+
+	if err := n.WalkLevelOrderWithMarker(nodeFunc, endOfLevel); err != nil {
+		panic(err)
+	}
+
+	return levels
+}
+
+// NodesAtLevel returns the number of nodes at the given depth relative
+// to the node, where level 0 is the node itself. It returns 0, if the
+// level is negative or beyond the height of the tree. The walk stops
+// as soon as the given level has been counted.
+func (n *Node[T]) NodesAtLevel(level int) int {
+	if level < 0 {
+		return 0
+	}
+
+	count := 0
+	result := 0
+	nodeFunc := func(node *Node[T]) error {
+		count++
+		return nil
+	}
+	endOfLevel := func(depth int) error {
+		if depth == level {
+			result = count
+			return ErrStopWalk
+		}
+		count = 0
+		return nil
+	}
+
+	if err := n.WalkLevelOrderWithMarker(nodeFunc, endOfLevel); err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+// AllPaths returns every path from the node down to a leaf node in
+// left-to-right order, where each path is the sequence of nodes from
+// the node down to the leaf. Each path is backed by its own array.
+func (n *Node[T]) AllPaths() [][]*Node[T] {
+	paths := make([][]*Node[T], 0)
+	path := make([]*Node[T], 0)
+	stack := newRingDeque[*nodeHeight[T]]()
+	stack.PushFront(&nodeHeight[T]{node: n, height: 0})
 
 	for !stack.IsEmpty() {
-		node, err := stack.PopFront()
+		item, err := stack.PopFront()
 		if err != nil {
 			panic(err)
 		}
 
-		left_height := -1
-		if node.Left != nil {
-			left_height = node.Left.Height()
-			stack.PushFront(node.Left)
+		path = append(path[:item.height], item.node)
+		if item.node.IsLeafNode() {
+			paths = append(paths, slices.Clone(path))
+			continue
 		}
 
-		right_height := -1
-		if node.Right != nil {
-			right_height = node.Right.Height()
-			stack.PushFront(node.Right)
+		if item.node.Right != nil {
+			stack.PushFront(&nodeHeight[T]{node: item.node.Right, height: item.height + 1})
+		}
+		if item.node.Left != nil {
+			stack.PushFront(&nodeHeight[T]{node: item.node.Left, height: item.height + 1})
 		}
+	}
 
-		left_height += 1
-		right_height += 1
-		diff := left_height - right_height
-		if diff < 0 {
-			diff = -diff
+	return paths
+}
+
+// PathTo returns the sequence of nodes from the node down to the
+// given target node, including both of them. Nodes are compared by
+// identity. The bool result is false, if the target is not reachable
+// from the node.
+func (n *Node[T]) PathTo(target *Node[T]) ([]*Node[T], bool) {
+	path := make([]*Node[T], 0)
+	stack := newRingDeque[*nodeHeight[T]]()
+	stack.PushFront(&nodeHeight[T]{node: n, height: 0})
+
+	for !stack.IsEmpty() {
+		item, err := stack.PopFront()
+		if err != nil {
+			panic(err)
 		}
 
-		if diff > 1 {
-			return false
+		path = append(path[:item.height], item.node)
+		if item.node == target {
+			return path, true
+		}
+
+		if item.node.Right != nil {
+			stack.PushFront(&nodeHeight[T]{node: item.node.Right, height: item.height + 1})
+		}
+		if item.node.Left != nil {
+			stack.PushFront(&nodeHeight[T]{node: item.node.Left, height: item.height + 1})
 		}
 	}
 
-	return true
+	return nil, false
 }
 
-// IsCompleteTree returns true, if the tree is complete. A complete
-// binary tree is a binary tree in which every level, except possibly
-// the last, is completely filled, and all nodes in the last level are
-// as far left as possible.
-func (n *Node[T]) IsCompleteTree() bool {
-	if n.IsLeafNode() {
-		return true
+// InsertBST inserts a new node with the given value into a Binary
+// Search Tree (BST) rooted at the node and returns the new node.
+// Values which compare equal to an existing value are inserted into
+// the right sub-tree of the existing node. If the bounds of the tree
+// are cached, they are updated along the insertion path, including
+// the ancestors of the node when inserting into a sub-tree.
+func (n *Node[T]) InsertBST(value T, cmp ComparatorFunc[T]) *Node[T] {
+	// The ancestors with cached bounds are collected upfront, since
+	// the insertion itself may invalidate them.
+	cached := n.subtreeMin != nil
+	ancestors := make([]*Node[T], 0)
+	for node := n.parent; cached && node != nil && node.subtreeMin != nil; node = node.parent {
+		ancestors = append(ancestors, node)
 	}
 
-	nonFullNodeSeen := false
-	queue := deque.New[*Node[T]]()
-	queue.PushBack(n)
+	path := make([]*Node[T], 0)
+	node := n
+	var inserted *Node[T]
 
-	for !queue.IsEmpty() {
-		node, err := queue.PopFront()
-		if err != nil {
-			panic(err)
+	for inserted == nil {
+		path = append(path, node)
+		if cmp(value, node.Value) < 0 {
+			if node.Left == nil {
+				inserted = node.InsertLeft(value)
+			}
+			node = node.Left
+		} else {
+			if node.Right == nil {
+				inserted = node.InsertRight(value)
+			}
+			node = node.Right
+		}
+	}
 
+	if cached {
+		inserted.updateBounds(cmp)
+		for i := len(path) - 1; i >= 0; i-- {
+			path[i].updateBounds(cmp)
 		}
+		for _, ancestor := range ancestors {
+			ancestor.updateBounds(cmp)
+		}
+	}
 
-		if node.Left != nil {
-			if nonFullNodeSeen {
-				return false
-			}
-			queue.PushBack(node.Left)
+	return inserted
+}
+
+// generatedNode is a node created by GenerateTree along with the
+// header byte, which describes its children.
+type generatedNode[T any] struct {
+	node   *Node[T]
+	header byte
+}
+
+// GenerateTree deterministically generates a tree from the given
+// data, which makes it suitable for fuzz and property-based testing.
+// Nodes are created in level-order, each consuming a header byte
+// followed by up to 3 value bytes, which are passed to makeValue.
+// The lowest two bits of the header specify whether the node has a
+// left and a right child, and the next two bits specify the number
+// of value bytes. Generation stops when data is exhausted. GenerateTree
+// returns nil, if data is empty.
+func GenerateTree[T any](data []byte, makeValue func([]byte) T) *Node[T] {
+	pos := 0
+	next := func() (T, byte) {
+		header := data[pos]
+		pos++
+		end := min(pos+int(header>>2&3), len(data))
+		value := makeValue(data[pos:end])
+		pos = end
+		return value, header
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	value, header := next()
+	root := NewNode(value)
+	queue := newRingDeque[*generatedNode[T]]()
+	queue.PushBack(&generatedNode[T]{node: root, header: header})
+
+	for !queue.IsEmpty() && pos < len(data) {
+		item, err := queue.PopFront()
+		if err != nil {
+			panic(err)
 		}
 
-		if !node.IsFullNode() {
-			nonFullNodeSeen = true
+		if item.header&1 != 0 && pos < len(data) {
+			value, header := next()
+			queue.PushBack(&generatedNode[T]{node: item.node.InsertLeft(value), header: header})
 		}
 
-		if node.Right != nil {
-			if nonFullNodeSeen {
-				return false
-			}
-			queue.PushBack(node.Right)
+		if item.header&2 != 0 && pos < len(data) {
+			value, header := next()
+			queue.PushBack(&generatedNode[T]{node: item.node.InsertRight(value), header: header})
 		}
 	}
 
-	return true
+	return root
 }
 
-// IsPerfectTree returns true, if the binary tree is full and complete
-func (n *Node[T]) IsPerfectTree() bool {
-	return n.IsFullTree() && n.IsCompleteTree()
+// FromSortedSlice builds a height-balanced Binary Search Tree (BST)
+// from the given sorted values by recursively picking the middle
+// value as the root of each sub-tree. It returns nil, if values is
+// empty.
+func FromSortedSlice[T any](values []T) *Node[T] {
+	if len(values) == 0 {
+		return nil
+	}
+
+	mid := len(values) / 2
+	root := NewNode(values[mid])
+	if left := FromSortedSlice(values[:mid]); left != nil {
+		left.parent = root
+		root.Left = left
+	}
+	if right := FromSortedSlice(values[mid+1:]); right != nil {
+		right.parent = root
+		root.Right = right
+	}
+
+	return root
 }
 
-// errNotBst is returned by a walking function when a tree being
-// walked is detected to not be a BST.
-var errNotBst = errors.New("not a binary search tree")
+// BalanceBST returns a new height-balanced Binary Search Tree (BST)
+// built from the values of the tree rooted at the node. The values are
+// collected in-order and sorted stably according to cmp, so that the
+// result is a valid BST even if the tree was not one. Only the values
+// are copied, and the tree rooted at the node is not modified.
+func (n *Node[T]) BalanceBST(cmp ComparatorFunc[T]) *Node[T] {
+	values := make([]T, 0)
+	walkFunc := func(node *Node[T]) error {
+		values = append(values, node.Value)
+		return nil
+	}
 
-// IsBinarySearchTree returns true, if the tree is a Binary Search
-// Tree (BST).
-func (n *Node[T]) IsBinarySearchTree(comparator ComparatorFunc[T]) bool {
-	if n.IsLeafNode() {
-		return true
+	if err := n.walkInOrder(noSkipNode[T], walkFunc); err != nil {
+		panic(err)
 	}
 
-	// Use errNotBst to signal a condition to stop walking the
-	// tree as soon as we know this is a not a BST.
-	var last *Node[T]
-	walkFunc := func(curr *Node[T]) error {
-		if last != nil && comparator(last.Value, curr.Value) > 0 {
-			return errNotBst
-		}
-		last = curr
+	slices.SortStableFunc(values, cmp)
 
-		return nil
+	return FromSortedSlice(values)
+}
+
+// replaceChild replaces the given child of the node with the new
+// child, and updates the parent link of the new child. It does nothing
+// but update the parent link, if the node is nil, or if the given
+// child is not a child of the node.
+func (n *Node[T]) replaceChild(child, newChild *Node[T]) {
+	if newChild != nil {
+		newChild.parent = n
 	}
 
-	err := n.WalkInOrder(walkFunc)
 	switch {
-	case err == errNotBst:
-		return false
-	case err != nil:
-		panic(err)
-	default:
-		return true
+	case n == nil:
+		return
+	case n.Left == child:
+		n.Left = newChild
+	case n.Right == child:
+		n.Right = newChild
 	}
 }
 
-// AddAttribute associates an attribute with the node, which will be
-// used when generating the Dot representation of the tree.
-func (n *Node[T]) AddAttribute(name, value string) {
-	n.dotAttributes[name] = value
+// RotateLeft performs a left rotation of the sub-tree rooted at the
+// node, which makes the right child of the node the new root of the
+// sub-tree, and returns it. The in-order sequence of the sub-tree is
+// preserved. If the node has a parent, its child link is updated to
+// refer to the new root. RotateLeft does nothing and returns the
+// node, if it has no right child.
+func (n *Node[T]) RotateLeft() *Node[T] {
+	pivot := n.Right
+	if pivot == nil {
+		return n
+	}
+
+	n.parent.replaceChild(n, pivot)
+	n.Right = pivot.Left
+	if n.Right != nil {
+		n.Right.parent = n
+	}
+	pivot.Left = n
+	n.parent = pivot
+	n.updateCaches()
+
+	return pivot
 }
 
-// GetDotAttributes returns the attributes associated with the node in
-// format suitable for using in the Dot representation.
-func (n *Node[T]) GetDotAttributes() string {
-	attrs := ""
-	for k, v := range n.dotAttributes {
-		attrs += fmt.Sprintf("%s=%s ", k, v)
+// RotateRight performs a right rotation of the sub-tree rooted at the
+// node, which makes the left child of the node the new root of the
+// sub-tree, and returns it. The in-order sequence of the sub-tree is
+// preserved. If the node has a parent, its child link is updated to
+// refer to the new root. RotateRight does nothing and returns the
+// node, if it has no left child.
+func (n *Node[T]) RotateRight() *Node[T] {
+	pivot := n.Left
+	if pivot == nil {
+		return n
 	}
 
-	return strings.TrimRight(attrs, " ")
+	n.parent.replaceChild(n, pivot)
+	n.Left = pivot.Right
+	if n.Left != nil {
+		n.Left.parent = n
+	}
+	pivot.Right = n
+	n.parent = pivot
+	n.updateCaches()
+
+	return pivot
 }
 
-// dotId returns the unique node id, which is used when generating the
-// binary tree representation in Dot.
-func (n *Node[T]) dotId() int64 {
-	addr := fmt.Sprintf("%p", n)
-	id, err := strconv.ParseInt(addr[2:], 16, 64)
-	if err != nil {
-		panic(err)
+// subtreeHeight returns the height of the sub-tree rooted at the given
+// node, or -1 if the node is nil.
+func subtreeHeight[T any](node *Node[T]) int {
+	if node == nil {
+		return -1
 	}
 
-	return id
+	return node.Height()
 }
 
-// WriteDot generates the Dot representation of the binary tree.
-func (n *Node[T]) WriteDot(w io.Writer) error {
-	nodeAttrs := `[color=lightblue fillcolor=lightblue fontcolor=black shape=record style="filled, rounded"]`
-	if _, err := fmt.Fprintln(w, "digraph {"); err != nil {
-		return err
+// rebalanceAVL restores the AVL balance of the sub-tree rooted at the
+// node by performing the needed rotations, and returns the new root
+// of the sub-tree. When the bounds are cached, they are updated for
+// the rotated nodes.
+func (n *Node[T]) rebalanceAVL(cmp ComparatorFunc[T]) *Node[T] {
+	cached := n.subtreeMin != nil
+	rotate := func(node *Node[T], rotation func(*Node[T]) *Node[T]) *Node[T] {
+		pivot := rotation(node)
+		if cached {
+			node.updateBounds(cmp)
+			pivot.updateBounds(cmp)
+		}
+		return pivot
 	}
 
-	if _, err := fmt.Fprintf(w, "\tnode %s\n", nodeAttrs); err != nil {
-		return err
+	balance := subtreeHeight(n.Left) - subtreeHeight(n.Right)
+	switch {
+	case balance > 1:
+		if subtreeHeight(n.Left.Left) < subtreeHeight(n.Left.Right) {
+			rotate(n.Left, (*Node[T]).RotateLeft)
+		}
+		return rotate(n, (*Node[T]).RotateRight)
+	case balance < -1:
+		if subtreeHeight(n.Right.Right) < subtreeHeight(n.Right.Left) {
+			rotate(n.Right, (*Node[T]).RotateRight)
+		}
+		return rotate(n, (*Node[T]).RotateLeft)
+	default:
+		return n
 	}
+}
 
-	walkFunc := func(n *Node[T]) error {
-		nodeId := n.dotId()
-		_, err := fmt.Fprintf(w, "\t%d [label=\"<l>|<v> %v|<r>\" %s]\n", nodeId, n.Value, n.GetDotAttributes())
-		if err != nil {
-			return err
+// InsertAVL inserts a new node with the given value into a Binary
+// Search Tree (BST) rooted at the node in the same way as InsertBST,
+// and then rebalances the tree along the insertion path using
+// rotations, so that an AVL tree remains height-balanced. Since the
+// root of the tree may change, InsertAVL returns the root of the tree
+// after the insertion. The parent links of the nodes are used for
+// walking the insertion path back up, so the tree should be built
+// using the Insert* methods. Rebalancing is O(log n) when the heights
+// are cached, see RecomputeHeights, and O(n) otherwise.
+func (n *Node[T]) InsertAVL(value T, cmp ComparatorFunc[T]) *Node[T] {
+	inserted := n.InsertBST(value, cmp)
+	for node := inserted.parent; node != nil; node = node.parent {
+		isRoot := node == n
+		node = node.rebalanceAVL(cmp)
+		if isRoot {
+			return node
 		}
+	}
 
-		if n.Left != nil {
-			if _, err := fmt.Fprintf(w, "\t%d:l -> %d:v\n", nodeId, n.Left.dotId()); err != nil {
-				return err
-			}
+	return n
+}
+
+// ErrInconsistentTraversals is the error which is returned when a tree
+// cannot be reconstructed from its traversals, because they differ in
+// length or contents.
+var ErrInconsistentTraversals = errors.New("inconsistent traversals")
+
+// LengthMismatchError is the error which is returned when a tree
+// cannot be reconstructed, because its traversals differ in length.
+// It wraps ErrInconsistentTraversals.
+type LengthMismatchError struct {
+	// Traversal is the name of the traversal, which is paired with
+	// the in-order traversal, e.g. "pre-order"
+	Traversal string
+
+	// Len is the length of the paired traversal
+	Len int
+
+	// InOrderLen is the length of the in-order traversal
+	InOrderLen int
+}
+
+// Error implements the error interface.
+func (e *LengthMismatchError) Error() string {
+	return fmt.Sprintf("%s: %s has %d values, in-order has %d values", ErrInconsistentTraversals, e.Traversal, e.Len, e.InOrderLen)
+}
+
+// Unwrap returns ErrInconsistentTraversals.
+func (e *LengthMismatchError) Unwrap() error {
+	return ErrInconsistentTraversals
+}
+
+// MissingValueError is the error which is returned when a tree cannot
+// be reconstructed, because a value from one of its traversals is
+// missing from the in-order traversal. It wraps
+// ErrInconsistentTraversals.
+type MissingValueError struct {
+	// Value is the missing value
+	Value any
+}
+
+// Error implements the error interface.
+func (e *MissingValueError) Error() string {
+	return fmt.Sprintf("%s: value %v is missing from in-order traversal", ErrInconsistentTraversals, e.Value)
+}
+
+// Unwrap returns ErrInconsistentTraversals.
+func (e *MissingValueError) Unwrap() error {
+	return ErrInconsistentTraversals
+}
+
+// inOrderPositions returns the position of each value within the
+// given in-order traversal.
+func inOrderPositions[T comparable](inorder []T) (map[T]int, error) {
+	positions := make(map[T]int, len(inorder))
+	for i, value := range inorder {
+		if _, ok := positions[value]; ok {
+			return nil, fmt.Errorf("%w: duplicate value %v in in-order traversal", ErrInconsistentTraversals, value)
+		}
+		positions[value] = i
+	}
+
+	return positions, nil
+}
+
+// buildFromTraversal reconstructs a tree from the values of the
+// given traversal taken in order, and the positions of the values
+// within the in-order traversal. When rightFirst is true the right
+// sub-tree of each node is built before the left one.
+func buildFromTraversal[T comparable](values []T, positions map[T]int, rightFirst bool) (*Node[T], error) {
+	next := 0
+	var build func(lo, hi int) (*Node[T], error)
+	build = func(lo, hi int) (*Node[T], error) {
+		if lo >= hi {
+			return nil, nil
 		}
 
-		if n.Right != nil {
-			if _, err := fmt.Fprintf(w, "\t%d:r -> %d:v\n", nodeId, n.Right.dotId()); err != nil {
-				return err
+		value := values[next]
+		next++
+		pos, ok := positions[value]
+		if !ok {
+			return nil, &MissingValueError{Value: value}
+		}
+		if pos < lo || pos >= hi {
+			return nil, fmt.Errorf("%w: value %v is out of place", ErrInconsistentTraversals, value)
+		}
+
+		node := NewNode(value)
+		var left, right *Node[T]
+		var err error
+		if rightFirst {
+			if right, err = build(pos+1, hi); err != nil {
+				return nil, err
+			}
+			if left, err = build(lo, pos); err != nil {
+				return nil, err
+			}
+		} else {
+			if left, err = build(lo, pos); err != nil {
+				return nil, err
+			}
+			if right, err = build(pos+1, hi); err != nil {
+				return nil, err
 			}
 		}
 
-		return nil
+		if left != nil {
+			left.parent = node
+			node.Left = left
+		}
+		if right != nil {
+			right.parent = node
+			node.Right = right
+		}
+
+		return node, nil
 	}
 
-	if err := n.WalkPreOrder(walkFunc); err != nil {
-		return err
+	return build(0, len(values))
+}
+
+// FromPreIn reconstructs a tree from its pre-order and in-order
+// traversals. The values of the tree are required to be distinct,
+// since otherwise the tree cannot be reconstructed unambiguously. An
+// error wrapping ErrInconsistentTraversals is returned, if the
+// traversals differ in length or contents, which is a
+// *LengthMismatchError or a *MissingValueError respectively, when
+// applicable. FromPreIn returns nil, if both traversals are empty.
+func FromPreIn[T comparable](preorder, inorder []T) (*Node[T], error) {
+	if len(preorder) != len(inorder) {
+		return nil, &LengthMismatchError{Traversal: "pre-order", Len: len(preorder), InOrderLen: len(inorder)}
 	}
 
-	if _, err := fmt.Fprintln(w, "}"); err != nil {
-		return err
+	positions, err := inOrderPositions(inorder)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return buildFromTraversal(preorder, positions, false)
+}
+
+// FromPostIn reconstructs a tree from its post-order and in-order
+// traversals, following the same contract as FromPreIn.
+func FromPostIn[T comparable](postorder, inorder []T) (*Node[T], error) {
+	if len(postorder) != len(inorder) {
+		return nil, &LengthMismatchError{Traversal: "post-order", Len: len(postorder), InOrderLen: len(inorder)}
+	}
+
+	positions, err := inOrderPositions(inorder)
+	if err != nil {
+		return nil, err
+	}
+
+	reversed := make([]T, len(postorder))
+	for i, value := range postorder {
+		reversed[len(postorder)-1-i] = value
+	}
+
+	return buildFromTraversal(reversed, positions, true)
 }
@@ -26,7 +26,13 @@ package binarytree_test
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -65,79 +71,126 @@ func TestHeightAndSize(t *testing.T) {
 	}
 }
 
-func TestIsLeafNode(t *testing.T) {
+func TestMinDepth(t *testing.T) {
 	// Our test tree
 	//
 	//     __1
 	//    /   \
 	//   2     3
-	//  / \
-	// 4   5
+	//  / \     \
+	// 4   5     6
+	//          /
+	//         7
 	//
-	root := binarytree.NewNode(1)
-	two := root.InsertLeft(2)
-	three := root.InsertRight(3)
-	four := two.InsertLeft(4)
-	five := two.InsertRight(5)
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Right(6).Left(7).Root()
 
-	if root.IsLeafNode() {
-		t.Fatal("root node should not be a leaf")
+	if got := root.MinDepth(); got != 2 {
+		t.Fatalf("want min depth 2, got %d", got)
 	}
 
-	if two.IsLeafNode() {
-		t.Fatal("node (2) should not be a leaf")
+	if got := root.Right.MinDepth(); got != 2 {
+		t.Fatalf("want min depth 2 from node (3), got %d", got)
 	}
 
-	if !three.IsLeafNode() {
-		t.Fatal("node (3) should be a leaf")
+	leaf := binarytree.NewNode(1)
+	if got := leaf.MinDepth(); got != 0 {
+		t.Fatalf("want min depth 0, got %d", got)
 	}
+}
 
-	if !four.IsLeafNode() {
-		t.Fatal("node (4) should not be a leaf")
+func TestNearestLeaf(t *testing.T) {
+	// The nearest leaf is a direct child
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	leaf, distance := root.NearestLeaf()
+	if leaf != root.Right || distance != 1 {
+		t.Fatalf("want nearest leaf (3) at distance 1, got (%d) at distance %d", leaf.Value, distance)
 	}
 
-	if !five.IsLeafNode() {
-		t.Fatal("node (5) should be a leaf")
+	// The nearest leaf is deeper
+	//
+	//     1
+	//    / \
+	//   2   3
+	//  /     \
+	// 4       5
+	//        /
+	//       6
+	//
+	root = binarytree.Build(1).Left(2).Left(4).Up().Up().Right(3).Right(5).Left(6).Root()
+	leaf, distance = root.NearestLeaf()
+	if leaf != root.Left.Left || distance != 2 {
+		t.Fatalf("want nearest leaf (4) at distance 2, got (%d) at distance %d", leaf.Value, distance)
+	}
+
+	// A leaf is nearest to itself
+	leaf, distance = root.Left.Left.NearestLeaf()
+	if leaf != root.Left.Left || distance != 0 {
+		t.Fatal("leaf node should be nearest to itself")
 	}
 }
 
-func TestIsFullNode(t *testing.T) {
+func TestDepthConventions(t *testing.T) {
 	// Our test tree
 	//
 	//     __1
 	//    /   \
 	//   2     3
-	//  / \
-	// 4   5
+	//  / \     \
+	// 4   5     6
+	//          /
+	//         7
 	//
-	root := binarytree.NewNode(1)
-	two := root.InsertLeft(2)
-	three := root.InsertRight(3)
-	four := two.InsertLeft(4)
-	five := two.InsertRight(5)
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Right(6).Left(7).Root()
 
-	if !root.IsFullNode() {
-		t.Fatal("root node should be full")
+	// The node a query starts from is at depth 0
+	if depth, ok := root.Depth(root); !ok || depth != 0 {
+		t.Fatal("root node should be at depth 0")
 	}
 
-	if !two.IsFullNode() {
-		t.Fatal("node (2) should be full")
+	leaf := binarytree.NewNode(1)
+	if leaf.Height() != 0 || leaf.MinDepth() != 0 {
+		t.Fatal("single node should have height and min depth 0")
 	}
 
-	if three.IsFullNode() {
-		t.Fatal("node (3) should not be full")
+	// The depth of the deepest node equals the height of the tree,
+	// and the depth of the nearest leaf equals the min depth.
+	deepest, nearest := 0, -1
+	walkFunc := func(node *binarytree.Node[int], depth int) error {
+		d, ok := root.Depth(node)
+		if !ok || d != depth {
+			t.Fatalf("depth mismatch for node (%d)", node.Value)
+		}
+
+		if depth > deepest {
+			deepest = depth
+		}
+		if node.IsLeafNode() && (nearest == -1 || depth < nearest) {
+			nearest = depth
+		}
+		return nil
 	}
 
-	if four.IsFullNode() {
-		t.Fatal("node (4) should not be full")
+	if err := root.WalkLevelOrderWithDepth(walkFunc); err != nil {
+		t.Fatal(err)
 	}
 
-	if five.IsFullNode() {
-		t.Fatal("node (5) should not be full")
+	if deepest != root.Height() {
+		t.Fatalf("want deepest node at depth %d, got %d", root.Height(), deepest)
+	}
+
+	if nearest != root.MinDepth() {
+		t.Fatalf("want nearest leaf at depth %d, got %d", root.MinDepth(), nearest)
 	}
 }
 
-func TestWalkInOrder(t *testing.T) {
+func TestParent(t *testing.T) {
 	// Our test tree
 	//
 	//     __1
@@ -148,58 +201,92 @@ func TestWalkInOrder(t *testing.T) {
 	//
 	root := binarytree.NewNode(1)
 	two := root.InsertLeft(2)
-	root.InsertRight(3)
-	two.InsertLeft(4)
-	two.InsertRight(5)
+	three := root.InsertRight(3)
+	four := two.InsertLeft(4)
+	five := two.InsertRight(5)
 
-	result := make([]int, 0)
-	wantResult := []int{4, 2, 5, 1, 3}
-	walkFunc := func(node *binarytree.Node[int]) error {
-		result = append(result, node.Value)
-		return nil
+	if root.Parent() != nil {
+		t.Fatal("root node should not have a parent")
 	}
 
-	if err := root.WalkInOrder(walkFunc); err != nil {
-		t.Fatal(err)
+	if two.Parent() != root || three.Parent() != root {
+		t.Fatal("parent of nodes (2) and (3) should be the root")
 	}
 
-	if !reflect.DeepEqual(result, wantResult) {
-		t.Fatalf("want in-order values %v, got %v", wantResult, result)
+	if four.Parent() != two || five.Parent() != two {
+		t.Fatal("parent of nodes (4) and (5) should be node (2)")
+	}
+
+	// Replacing a child detaches the old one
+	six := two.InsertLeft(6)
+	if six.Parent() != two {
+		t.Fatal("parent of node (6) should be node (2)")
+	}
+
+	if four.Parent() != nil {
+		t.Fatal("replaced node (4) should not have a parent")
+	}
+
+	seven := two.InsertRight(7)
+	if seven.Parent() != two || five.Parent() != nil {
+		t.Fatal("replaced node (5) should not have a parent")
 	}
 }
 
-func TestWalkPreOrder(t *testing.T) {
-	// Our test tree
+func TestInsertNode(t *testing.T) {
+	// Our test trees
 	//
-	//     __1
-	//    /   \
-	//   2     3
-	//  / \
-	// 4   5
+	//    1        5
+	//   / \      / \
+	//  2   3    6   7
 	//
-	root := binarytree.NewNode(1)
-	two := root.InsertLeft(2)
-	root.InsertRight(3)
-	two.InsertLeft(4)
-	two.InsertRight(5)
+	root := binarytree.Build(1).Left(2).Up().Right(3).Root()
+	other := binarytree.Build(5).Left(6).Up().Right(7).Root()
+	two := root.Left
 
-	result := make([]int, 0)
-	wantResult := []int{1, 2, 4, 5, 3}
-	walkFunc := func(node *binarytree.Node[int]) error {
-		result = append(result, node.Value)
-		return nil
+	// Graft the other tree as the left child of node (3)
+	if got := root.Right.InsertLeftNode(other); got != other {
+		t.Fatal("InsertLeftNode should return the attached node")
 	}
 
-	if err := root.WalkPreOrder(walkFunc); err != nil {
-		t.Fatal(err)
+	if other.Parent() != root.Right || root.Right.Left != other {
+		t.Fatal("node (5) should be the left child of node (3)")
 	}
 
-	if !reflect.DeepEqual(wantResult, result) {
-		t.Fatalf("want pre-order values %v, got %v", wantResult, result)
+	want := []int{1, 2, 3, 5, 6, 7}
+	if got := root.ToSlice(binarytree.PreOrder); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want pre-order %v, got %v", want, got)
+	}
+
+	// Moving a node detaches it from its previous parent
+	six := other.Left
+	root.InsertRightNode(six)
+	if other.Left != nil || six.Parent() != root || root.Right != six {
+		t.Fatal("node (6) should be moved to the right of the root")
+	}
+
+	// Replaced children are detached
+	root.InsertLeftNode(binarytree.NewNode(8))
+	if two.Parent() != nil {
+		t.Fatal("replaced node (2) should be detached")
+	}
+
+	// Attaching nil clears the child
+	if got := root.InsertLeftNode(nil); got != nil || root.Left != nil {
+		t.Fatal("attaching nil should clear the left child")
+	}
+
+	// Cached heights and sizes are maintained
+	root = binarytree.Build(1).Left(2).Root()
+	root.RecomputeHeights()
+	root.RecomputeSizes()
+	root.InsertRightNode(binarytree.Build(3).Left(4).Left(5).Root())
+	if root.Height() != 3 || root.Size() != 5 {
+		t.Fatalf("want height 3 and size 5, got %d and %d", root.Height(), root.Size())
 	}
 }
 
-func TestWalkPostOrder(t *testing.T) {
+func TestRemove(t *testing.T) {
 	// Our test tree
 	//
 	//     __1
@@ -210,27 +297,76 @@ func TestWalkPostOrder(t *testing.T) {
 	//
 	root := binarytree.NewNode(1)
 	two := root.InsertLeft(2)
-	root.InsertRight(3)
+	three := root.InsertRight(3)
 	two.InsertLeft(4)
 	two.InsertRight(5)
 
-	result := make([]int, 0)
-	wantResult := []int{4, 5, 2, 3, 1}
-	walkFunc := func(node *binarytree.Node[int]) error {
-		result = append(result, node.Value)
-		return nil
+	if root.Remove() {
+		t.Fatal("root node cannot be removed")
 	}
 
-	if err := root.WalkPostOrder(walkFunc); err != nil {
-		t.Fatal(err)
+	if !two.Remove() {
+		t.Fatal("node (2) should be removed")
 	}
 
-	if !reflect.DeepEqual(wantResult, result) {
-		t.Fatalf("want post-order values %v, got %v", wantResult, result)
+	if root.Left != nil || two.Parent() != nil {
+		t.Fatal("node (2) should be detached from the root")
+	}
+
+	if root.Size() != 2 || root.Height() != 1 {
+		t.Fatal("original tree should have size 2 and height 1")
+	}
+
+	// The removed sub-tree is an independent tree
+	if two.Size() != 3 || two.Height() != 1 {
+		t.Fatal("removed sub-tree should have size 3 and height 1")
+	}
+
+	// A node with a stale parent link
+	root.Right = nil
+	if three.Remove() {
+		t.Fatal("node (3) with stale parent link should not be removed")
+	}
+
+	if three.Parent() != nil {
+		t.Fatal("stale parent link of node (3) should be cleared")
 	}
 }
 
-func TestWalkLevelOrder(t *testing.T) {
+func TestSizeAllocations(t *testing.T) {
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	root.AddSkipNodeFunc(func(n *binarytree.Node[int]) bool {
+		return n.Value == 3
+	})
+
+	size := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		size = root.Size()
+	})
+
+	if size != 5 {
+		t.Fatalf("want size 5, got %d", size)
+	}
+
+	if allocs != 0 {
+		t.Fatalf("want zero allocations, got %f", allocs)
+	}
+
+	// The skip node handlers are honored by SizeWalked
+	allocs = testing.AllocsPerRun(100, func() {
+		size = root.SizeWalked()
+	})
+
+	if size != 4 {
+		t.Fatalf("want walked size 4, got %d", size)
+	}
+
+	if allocs != 0 {
+		t.Fatalf("want zero allocations, got %f", allocs)
+	}
+}
+
+func TestRecomputeSizes(t *testing.T) {
 	// Our test tree
 	//
 	//     __1
@@ -239,113 +375,202 @@ func TestWalkLevelOrder(t *testing.T) {
 	//  / \
 	// 4   5
 	//
-	root := binarytree.NewNode(1)
-	two := root.InsertLeft(2)
-	root.InsertRight(3)
-	two.InsertLeft(4)
-	two.InsertRight(5)
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	root.RecomputeSizes()
 
-	result := make([]int, 0)
-	wantResult := []int{1, 2, 3, 4, 5}
+	wantSizes := map[int]int{1: 5, 2: 3, 3: 1, 4: 1, 5: 1}
 	walkFunc := func(node *binarytree.Node[int]) error {
-		result = append(result, node.Value)
+		if got := node.Size(); got != wantSizes[node.Value] {
+			t.Fatalf("want size %d of node (%d), got %d", wantSizes[node.Value], node.Value, got)
+		}
 		return nil
 	}
 
-	if err := root.WalkLevelOrder(walkFunc); err != nil {
+	if err := root.WalkPreOrder(walkFunc); err != nil {
 		t.Fatal(err)
 	}
 
-	if !reflect.DeepEqual(wantResult, result) {
-		t.Fatalf("want level-order values %v, got %v", wantResult, result)
+	// Insertions maintain the cache
+	six := root.Right.InsertLeft(6)
+	six.InsertRight(7)
+	if got := root.Size(); got != 7 {
+		t.Fatalf("want size 7, got %d", got)
 	}
-}
 
-func TestSkipNodeHandlers(t *testing.T) {
-	// Construct the following simple binary tree
-	//
-	//     __1
-	//    /   \
-	//   2     3
-	//  / \
-	// 4   5
-	//
-	root := binarytree.NewNode(1)
-	two := root.InsertLeft(2)
-	root.InsertRight(3)
-	two.InsertLeft(4)
-	two.InsertRight(5)
+	if got := root.Right.Size(); got != 3 {
+		t.Fatalf("want size 3 of node (3), got %d", got)
+	}
 
-	skipFunc := func(n *binarytree.Node[int]) bool {
-		// Skip the sub-tree at node (2)
-		if n.Value == 2 {
-			return true
-		}
+	// Replacing a child maintains the cache
+	root.Right.InsertLeft(8)
+	if got := root.Size(); got != 6 {
+		t.Fatalf("want size 6, got %d", got)
+	}
 
-		return false
+	// Removals maintain the cache
+	root.Left.Remove()
+	if got := root.Size(); got != 3 {
+		t.Fatalf("want size 3, got %d", got)
 	}
 
-	values := make([]int, 0)
-	walkFunc := func(n *binarytree.Node[int]) error {
-		values = append(values, n.Value)
-		return nil
+	// Direct mutations bypass the cache until recomputed
+	root.Left = binarytree.Build(9).Left(10).Root()
+	if got := root.Size(); got != 3 {
+		t.Fatalf("want stale size 3, got %d", got)
 	}
 
-	root.AddSkipNodeFunc(skipFunc)
-	if err := root.WalkInOrder(walkFunc); err != nil {
-		t.Fatal(err)
+	root.RecomputeSizes()
+	if got := root.Size(); got != 5 {
+		t.Fatalf("want size 5, got %d", got)
 	}
 
-	wantValues := []int{1, 3}
-	if !reflect.DeepEqual(values, wantValues) {
-		t.Fatalf("want in-order values %v, got %v", wantValues, values)
+	// The cache is O(1) and allocation free
+	allocs := testing.AllocsPerRun(100, func() {
+		root.Size()
+	})
+
+	if allocs != 0 {
+		t.Fatalf("want zero allocations, got %f", allocs)
 	}
 }
 
-func TestFindNode(t *testing.T) {
-	// Construct the following simple binary tree
-	//
-	//     __1
-	//    /   \
-	//   2     3
-	//  / \
-	// 4   5
-	//
-	root := binarytree.NewNode(1)
-	two := root.InsertLeft(2)
-	root.InsertRight(3)
-	two.InsertLeft(4)
-	two.InsertRight(5)
+func BenchmarkSize(b *testing.B) {
+	// A complete tree with 64k nodes
+	root := binarytree.NewNode(0)
+	nodes := []*binarytree.Node[int]{root}
+	for i := 1; i < 1<<16; i += 2 {
+		parent := nodes[0]
+		nodes = append(nodes[1:], parent.InsertLeft(i), parent.InsertRight(i+1))
+	}
 
-	goodPredicate := func(n *binarytree.Node[int]) bool {
-		if n.Value == 2 {
-			return true
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		root.Size()
+	}
+}
+
+func TestWalkLargeTree(t *testing.T) {
+	values := make([]int, 5000)
+	for i := range values {
+		values[i] = i
+	}
+	root := binarytree.FromSortedSlice(values)
+
+	// Reference traversals
+	pre, post := make([]int, 0), make([]int, 0)
+	var walk func(node *binarytree.Node[int])
+	walk = func(node *binarytree.Node[int]) {
+		if node == nil {
+			return
 		}
-		return false
+		pre = append(pre, node.Value)
+		walk(node.Left)
+		walk(node.Right)
+		post = append(post, node.Value)
 	}
+	walk(root)
 
-	node, ok := root.FindNode(goodPredicate)
-	if !ok {
-		t.Fatal("unable to find node (2)")
+	level := make([]int, 0)
+	queue := []*binarytree.Node[int]{root}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		level = append(level, node.Value)
+		for _, child := range []*binarytree.Node[int]{node.Left, node.Right} {
+			if child != nil {
+				queue = append(queue, child)
+			}
+		}
 	}
 
-	// The node we are looking for should have left and right
-	// children
-	if node.Left == nil || node.Right == nil {
-		t.Fatal("node (2) does not have left or right children")
+	tests := []struct {
+		order binarytree.TraversalOrder
+		want  []int
+	}{
+		{order: binarytree.InOrder, want: values},
+		{order: binarytree.PreOrder, want: pre},
+		{order: binarytree.PostOrder, want: post},
+		{order: binarytree.LevelOrder, want: level},
 	}
 
-	// No node will match is supposed to match with this predicate
-	badPredicate := func(n *binarytree.Node[int]) bool {
-		return false
+	for _, test := range tests {
+		if got := root.ToSlice(test.order); !reflect.DeepEqual(got, test.want) {
+			t.Fatalf("unexpected walk of large tree in order %d", test.order)
+		}
 	}
+}
 
-	if _, ok := root.FindNode(badPredicate); ok {
-		t.Fatal("no node is supposed to match the predicate")
+func BenchmarkWalk(b *testing.B) {
+	// A complete tree with 100k nodes
+	values := make([]int, 100000)
+	for i := range values {
+		values[i] = i
+	}
+	root := binarytree.FromSortedSlice(values)
+	walkFunc := func(node *binarytree.Node[int]) error {
+		return nil
+	}
+
+	walkers := []struct {
+		name string
+		walk func(walkFunc binarytree.WalkFunc[int]) error
+	}{
+		{name: "in-order", walk: root.WalkInOrder},
+		{name: "pre-order", walk: root.WalkPreOrder},
+		{name: "post-order", walk: root.WalkPostOrder},
+		{name: "level-order", walk: root.WalkLevelOrder},
+	}
+
+	for _, walker := range walkers {
+		b.Run(walker.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := walker.walk(walkFunc); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
 	}
 }
 
-func TestIsFullTree(t *testing.T) {
+func BenchmarkWalkDegenerate(b *testing.B) {
+	// A degenerate tree with 50k nodes, zig-zagging between left
+	// and right children
+	root := binarytree.NewNode(0)
+	node := root
+	for i := 1; i < 50000; i++ {
+		if i%2 == 0 {
+			node = node.InsertLeft(i)
+		} else {
+			node = node.InsertRight(i)
+		}
+	}
+	walkFunc := func(node *binarytree.Node[int]) error {
+		return nil
+	}
+
+	walkers := []struct {
+		name string
+		walk func(walkFunc binarytree.WalkFunc[int]) error
+	}{
+		{name: "pre-order", walk: root.WalkPreOrder},
+		{name: "post-order", walk: root.WalkPostOrder},
+	}
+
+	for _, walker := range walkers {
+		b.Run(walker.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := walker.walk(walkFunc); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestIsLeafNode(t *testing.T) {
 	// Our test tree
 	//
 	//     __1
@@ -353,551 +578,4964 @@ func TestIsFullTree(t *testing.T) {
 	//   2     3
 	//  / \
 	// 4   5
+	//
 	root := binarytree.NewNode(1)
 	two := root.InsertLeft(2)
-	root.InsertRight(3)
-	two.InsertLeft(4)
-	two.InsertRight(5)
+	three := root.InsertRight(3)
+	four := two.InsertLeft(4)
+	five := two.InsertRight(5)
 
-	if !root.IsFullTree() {
-		t.Fatal("tree should be full")
+	if root.IsLeafNode() {
+		t.Fatal("root node should not be a leaf")
+	}
+
+	if two.IsLeafNode() {
+		t.Fatal("node (2) should not be a leaf")
+	}
+
+	if !three.IsLeafNode() {
+		t.Fatal("node (3) should be a leaf")
+	}
+
+	if !four.IsLeafNode() {
+		t.Fatal("node (4) should not be a leaf")
+	}
+
+	if !five.IsLeafNode() {
+		t.Fatal("node (5) should be a leaf")
 	}
 }
 
-func TestIsNotFullTree(t *testing.T) {
+func TestCountLeavesAndInternalNodes(t *testing.T) {
 	// Our test tree
 	//
 	//     __1
-	//    /
-	//   2
+	//    /   \
+	//   2     3
 	//  / \
 	// 4   5
 	//
-	root := binarytree.NewNode(1)
-	two := root.InsertLeft(2)
-	two.InsertLeft(4)
-	two.InsertRight(5)
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
 
-	if root.IsFullTree() {
-		t.Fatal("tree should not be full")
+	if got := root.CountLeaves(); got != 3 {
+		t.Fatalf("want 3 leaves, got %d", got)
+	}
+
+	if got := root.CountInternalNodes(); got != 2 {
+		t.Fatalf("want 2 internal nodes, got %d", got)
+	}
+
+	// A single root node is a leaf
+	leaf := binarytree.NewNode(1)
+	if leaf.CountLeaves() != 1 || leaf.CountInternalNodes() != 0 {
+		t.Fatal("single root node should be counted as a leaf")
 	}
 }
 
-func TestTreeIsDegenerateTree(t *testing.T) {
+func TestIsFullNode(t *testing.T) {
 	// Our test tree
 	//
-	//     1
-	//    /
-	//   2
-	//    \
-	//     3
-	//    /
-	//   4
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
 	//
 	root := binarytree.NewNode(1)
 	two := root.InsertLeft(2)
-	three := two.InsertRight(3)
-	three.InsertLeft(4)
+	three := root.InsertRight(3)
+	four := two.InsertLeft(4)
+	five := two.InsertRight(5)
 
-	if !root.IsDegenerateTree() {
-		t.Fatal("tree should be degenerate")
+	if !root.IsFullNode() {
+		t.Fatal("root node should be full")
+	}
+
+	if !two.IsFullNode() {
+		t.Fatal("node (2) should be full")
+	}
+
+	if three.IsFullNode() {
+		t.Fatal("node (3) should not be full")
+	}
+
+	if four.IsFullNode() {
+		t.Fatal("node (4) should not be full")
+	}
+
+	if five.IsFullNode() {
+		t.Fatal("node (5) should not be full")
 	}
 }
 
-func TestTreeIsNotDegenerate(t *testing.T) {
+func TestWalkInOrder(t *testing.T) {
 	// Our test tree
 	//
 	//     __1
-	//    /
-	//   2
+	//    /   \
+	//   2     3
 	//  / \
 	// 4   5
 	//
 	root := binarytree.NewNode(1)
 	two := root.InsertLeft(2)
+	root.InsertRight(3)
 	two.InsertLeft(4)
 	two.InsertRight(5)
 
-	if root.IsDegenerateTree() {
-		t.Fatal("tree should not be degenerate")
+	result := make([]int, 0)
+	wantResult := []int{4, 2, 5, 1, 3}
+	walkFunc := func(node *binarytree.Node[int]) error {
+		result = append(result, node.Value)
+		return nil
 	}
-}
 
-func TestIsBalancedTree(t *testing.T) {
-	// Unbalanced tree
-	//
-	//     1
-	//    /
-	//   2
-	//  /
-	// 3
-	//
-	unbalanced_root := binarytree.NewNode(1)
-	two := unbalanced_root.InsertLeft(2)
-	two.InsertLeft(3)
+	if err := root.WalkInOrder(walkFunc); err != nil {
+		t.Fatal(err)
+	}
 
-	if unbalanced_root.IsBalancedTree() {
-		t.Fatal("tree should not be balanced")
+	if !reflect.DeepEqual(result, wantResult) {
+		t.Fatalf("want in-order values %v, got %v", wantResult, result)
 	}
+}
 
-	// Another unbalanced tree
+func TestToSlice(t *testing.T) {
+	// Our test tree
 	//
-	//         __1
-	//        /   \
-	//     __2     3
+	//     __1
 	//    /   \
-	//   4     5
+	//   2     3
 	//  / \
-	// 6   7
-	unbalanced_root = binarytree.NewNode(1)
-	unbalanced_root.InsertRight(3)
-	two = unbalanced_root.InsertLeft(2)
-	two.InsertRight(5)
-	four := two.InsertLeft(4)
-	four.InsertLeft(6)
-	four.InsertRight(7)
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
 
-	if unbalanced_root.IsBalancedTree() {
-		t.Fatal("tree should not be balanced")
+	tests := []struct {
+		order binarytree.TraversalOrder
+		want  []int
+	}{
+		{order: binarytree.InOrder, want: []int{4, 2, 5, 1, 3}},
+		{order: binarytree.PreOrder, want: []int{1, 2, 4, 5, 3}},
+		{order: binarytree.PostOrder, want: []int{4, 5, 2, 3, 1}},
+		{order: binarytree.LevelOrder, want: []int{1, 2, 3, 4, 5}},
 	}
 
-	// A single root node is a balanced tree
-	leaf := binarytree.NewNode(1)
-	if !leaf.IsBalancedTree() {
-		t.Fatal("single leaf node should be balanced")
+	for _, test := range tests {
+		if got := root.ToSlice(test.order); !reflect.DeepEqual(got, test.want) {
+			t.Fatalf("want %v for order %d, got %v", test.want, test.order, got)
+		}
 	}
 
-	// Yet another unbalanced tree
+	// Skipped nodes are not included
+	root.AddSkipNodeFunc(func(node *binarytree.Node[int]) bool {
+		return node.Value == 2
+	})
+	want := []int{1, 3}
+	if got := root.ToSlice(binarytree.PreOrder); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for invalid traversal order")
+		}
+	}()
+	root.ToSlice(binarytree.TraversalOrder(-1))
+}
+
+func TestSequenceEqual(t *testing.T) {
+	// Our test tree
 	//
 	//     __1
-	//    /
-	//   2
+	//    /   \
+	//   2     3
 	//  / \
 	// 4   5
 	//
-	unbalanced_root = binarytree.NewNode(1)
-	two = unbalanced_root.InsertLeft(2)
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(2)
+	root.InsertRight(3)
 	two.InsertLeft(4)
 	two.InsertRight(5)
 
-	if unbalanced_root.IsBalancedTree() {
-		t.Fatal("tree should not be balanced")
+	eq := func(a, b int) bool { return a == b }
+	if !root.SequenceEqual(binarytree.InOrder, []int{4, 2, 5, 1, 3}, eq) {
+		t.Fatal("in-order sequence should match")
 	}
 
-	// The sub-tree with root node (2) is balanced
-	if !two.IsBalancedTree() {
-		t.Fatal("tree with root (2) should be balanced")
+	if root.SequenceEqual(binarytree.InOrder, []int{4, 2, 5, 1}, eq) {
+		t.Fatal("shorter sequence should not match")
 	}
 
-	// A balanced tree
-	//
-	//   1__
-	//  /   \
-	// 2     3
-	//      / \
-	//     4   5
-	balanced_root := binarytree.NewNode(1)
-	balanced_root.InsertLeft(2)
-	three := balanced_root.InsertRight(3)
-	three.InsertLeft(4)
-	three.InsertRight(5)
+	if root.SequenceEqual(binarytree.InOrder, []int{4, 2, 5, 1, 3, 6}, eq) {
+		t.Fatal("longer sequence should not match")
+	}
 
-	if !balanced_root.IsBalancedTree() {
-		t.Fatal("tree should be balanced")
+	if root.SequenceEqual(binarytree.PreOrder, []int{4, 2, 5, 1, 3}, eq) {
+		t.Fatal("pre-order sequence should not match")
+	}
+
+	if root.SequenceEqual(binarytree.TraversalOrder(42), []int{4, 2, 5, 1, 3}, eq) {
+		t.Fatal("invalid traversal order should not match")
 	}
 }
 
-func TestIsCompleteTree(t *testing.T) {
-	// A complete binary tree
+func TestWalkInOrderMorris(t *testing.T) {
+	// Our test tree
 	//
-	//    1
-	//   / \
-	//  2   3
+	//      ____8
+	//     /     \
+	//    3__     10__
+	//   /   \        \
+	//  1     6        14
+	//       / \      /
+	//      4   7    13
 	//
-	root := binarytree.NewNode(1)
-	root.InsertLeft(2)
-	root.InsertRight(3)
+	root := binarytree.NewNode(8)
+	three := root.InsertLeft(3)
+	three.InsertLeft(1)
+	six := three.InsertRight(6)
+	six.InsertLeft(4)
+	six.InsertRight(7)
+	ten := root.InsertRight(10)
+	fourteen := ten.InsertRight(14)
+	fourteen.InsertLeft(13)
 
-	if !root.IsCompleteTree() {
-		t.Fatal("tree should be complete")
-	}
+	clone := root.Clone()
 
-	// A complete binary tree
-	//
-	//      1
-	//     / \
-	//    2   3
-	//   /
-	//  4
-	//
-	root = binarytree.NewNode(1)
-	root.InsertRight(3)
-	two := root.InsertLeft(2)
-	two.InsertLeft(4)
+	result := make([]int, 0)
+	wantResult := []int{1, 3, 4, 6, 7, 8, 10, 13, 14}
+	walkFunc := func(node *binarytree.Node[int]) error {
+		result = append(result, node.Value)
+		return nil
+	}
 
-	if !root.IsCompleteTree() {
-		t.Fatal("tree should be complete")
+	if err := root.WalkInOrderMorris(walkFunc); err != nil {
+		t.Fatal(err)
 	}
 
-	// A complete binary tree
-	//
-	//     __1__
-	//    /     \
-	//   2       3
-	//  / \     /
-	// 4   5   6
-	//
-	root = binarytree.NewNode(1)
-	two = root.InsertLeft(2)
-	two.InsertLeft(4)
-	two.InsertRight(5)
-	three := root.InsertRight(3)
-	three.InsertLeft(6)
+	if !reflect.DeepEqual(result, wantResult) {
+		t.Fatalf("want in-order values %v, got %v", wantResult, result)
+	}
 
-	if !root.IsCompleteTree() {
-		t.Fatal("tree should be complete")
+	if !binarytree.Equal(root, clone) {
+		t.Fatal("tree should be restored after walking")
 	}
 
-	// Not complete binary tree
-	//
-	//     __1_
-	//    /    \
-	//   2      3
-	//  / \      \
-	// 4   5      6
-	//
-	root = binarytree.NewNode(1)
-	two = root.InsertLeft(2)
-	two.InsertLeft(4)
-	two.InsertRight(5)
-	three = root.InsertRight(3)
-	three.InsertRight(6)
+	// Stop the walk midway
+	errStop := errors.New("stop")
+	result = make([]int, 0)
+	stopFunc := func(node *binarytree.Node[int]) error {
+		if node.Value == 6 {
+			return errStop
+		}
+		result = append(result, node.Value)
+		return nil
+	}
 
-	if root.IsCompleteTree() {
-		t.Fatal("tree should not be complete")
+	if err := root.WalkInOrderMorris(stopFunc); err != errStop {
+		t.Fatalf("want stop error, got %v", err)
 	}
 
-	// Not complete binary tree
+	wantResult = []int{1, 3, 4}
+	if !reflect.DeepEqual(result, wantResult) {
+		t.Fatalf("want in-order values %v, got %v", wantResult, result)
+	}
+
+	if !binarytree.Equal(root, clone) {
+		t.Fatal("tree should be restored after stopping the walk")
+	}
+}
+
+func TestWalkReverseInOrder(t *testing.T) {
+	// Our test tree
 	//
-	//     1
-	//    /
-	//   2
-	//  /
-	// 3
+	//      ____8
+	//     /     \
+	//    3__     10__
+	//   /   \        \
+	//  1     6        14
+	//       / \      /
+	//      4   7    13
 	//
-	root = binarytree.NewNode(1)
-	two = root.InsertLeft(2)
-	two.InsertLeft(3)
+	root := binarytree.NewNode(8)
+	three := root.InsertLeft(3)
+	three.InsertLeft(1)
+	six := three.InsertRight(6)
+	six.InsertLeft(4)
+	six.InsertRight(7)
+	ten := root.InsertRight(10)
+	fourteen := ten.InsertRight(14)
+	fourteen.InsertLeft(13)
 
-	if root.IsCompleteTree() {
-		t.Fatal("tree should not be complete")
+	result := make([]int, 0)
+	wantResult := []int{14, 13, 10, 8, 7, 6, 4, 3, 1}
+	walkFunc := func(node *binarytree.Node[int]) error {
+		result = append(result, node.Value)
+		return nil
 	}
 
-	// Not complete binary tree
-	//
-	//   __1__
-	//  /     \
-	// 2       3
-	//  \     / \
-	//   4   5   6
-	root = binarytree.NewNode(1)
-	two = root.InsertLeft(2)
-	two.InsertRight(4)
-	three = root.InsertRight(3)
-	three.InsertLeft(5)
-	three.InsertRight(6)
+	if err := root.WalkReverseInOrder(walkFunc); err != nil {
+		t.Fatal(err)
+	}
 
-	if root.IsCompleteTree() {
-		t.Fatal("tree should not be complete")
+	if !reflect.DeepEqual(result, wantResult) {
+		t.Fatalf("want reverse in-order values %v, got %v", wantResult, result)
 	}
 
-	// Not complete binary tree
+	// Skip the sub-tree at node (6)
+	root.AddSkipNodeFunc(func(n *binarytree.Node[int]) bool {
+		return n.Value == 6
+	})
+
+	result = make([]int, 0)
+	wantResult = []int{14, 13, 10, 8, 3, 1}
+	if err := root.WalkReverseInOrder(walkFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(result, wantResult) {
+		t.Fatalf("want reverse in-order values %v, got %v", wantResult, result)
+	}
+}
+
+func TestWalkPreOrder(t *testing.T) {
+	// Our test tree
 	//
-	//   1__
-	//  /   \
-	// 2     3
-	//      / \
-	//     4   5
-	root = binarytree.NewNode(1)
-	root.InsertLeft(2)
-	three = root.InsertRight(3)
-	three.InsertLeft(4)
-	three.InsertRight(5)
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(2)
+	root.InsertRight(3)
+	two.InsertLeft(4)
+	two.InsertRight(5)
 
-	if root.IsCompleteTree() {
-		t.Fatal("tree should not be complete")
+	result := make([]int, 0)
+	wantResult := []int{1, 2, 4, 5, 3}
+	walkFunc := func(node *binarytree.Node[int]) error {
+		result = append(result, node.Value)
+		return nil
 	}
 
-	// A complete binary tree with a single root node
-	root = binarytree.NewNode(1)
-	if !root.IsCompleteTree() {
-		t.Fatal("tree should be complete")
+	if err := root.WalkPreOrder(walkFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(wantResult, result) {
+		t.Fatalf("want pre-order values %v, got %v", wantResult, result)
 	}
 }
 
-func TestIsPerfectTree(t *testing.T) {
-	// A perfect binary tree
+func TestWalkPostOrder(t *testing.T) {
+	// Our test tree
 	//
-	//    1
-	//   / \
-	//  2   3
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
 	//
 	root := binarytree.NewNode(1)
-	root.InsertLeft(2)
+	two := root.InsertLeft(2)
 	root.InsertRight(3)
+	two.InsertLeft(4)
+	two.InsertRight(5)
 
-	if !root.IsPerfectTree() {
-		t.Fatal("tree should be perfect")
+	result := make([]int, 0)
+	wantResult := []int{4, 5, 2, 3, 1}
+	walkFunc := func(node *binarytree.Node[int]) error {
+		result = append(result, node.Value)
+		return nil
 	}
 
-	// A non-perfect binary tree
+	if err := root.WalkPostOrder(walkFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(wantResult, result) {
+		t.Fatalf("want post-order values %v, got %v", wantResult, result)
+	}
+}
+
+func TestWalkLevelOrder(t *testing.T) {
+	// Our test tree
 	//
-	//      1
-	//     / \
-	//    2   3
-	//   /
-	//  4
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
 	//
-	root = binarytree.NewNode(1)
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(2)
 	root.InsertRight(3)
+	two.InsertLeft(4)
+	two.InsertRight(5)
+
+	result := make([]int, 0)
+	wantResult := []int{1, 2, 3, 4, 5}
+	walkFunc := func(node *binarytree.Node[int]) error {
+		result = append(result, node.Value)
+		return nil
+	}
+
+	if err := root.WalkLevelOrder(walkFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(wantResult, result) {
+		t.Fatalf("want level-order values %v, got %v", wantResult, result)
+	}
+}
+
+func TestSkipNodeHandlers(t *testing.T) {
+	// Construct the following simple binary tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.NewNode(1)
 	two := root.InsertLeft(2)
+	root.InsertRight(3)
 	two.InsertLeft(4)
+	two.InsertRight(5)
 
-	if root.IsPerfectTree() {
-		t.Fatal("tree should not be perfect")
+	skipFunc := func(n *binarytree.Node[int]) bool {
+		// Skip the sub-tree at node (2)
+		if n.Value == 2 {
+			return true
+		}
+
+		return false
 	}
 
-	// A non-perfect binary tree
+	values := make([]int, 0)
+	walkFunc := func(n *binarytree.Node[int]) error {
+		values = append(values, n.Value)
+		return nil
+	}
+
+	root.AddSkipNodeFunc(skipFunc)
+	if err := root.WalkInOrder(walkFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	wantValues := []int{1, 3}
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Fatalf("want in-order values %v, got %v", wantValues, values)
+	}
+}
+
+func TestSkipNodeHandlersSubtree(t *testing.T) {
+	// Construct the following simple binary tree
 	//
-	//     __1__
-	//    /     \
-	//   2       3
-	//  / \     /
-	// 4   5   6
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
 	//
-	root = binarytree.NewNode(1)
-	two = root.InsertLeft(2)
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	two := root.Left
+
+	skipValue := func(value int) binarytree.SkipNodeFunc[int] {
+		return func(n *binarytree.Node[int]) bool {
+			return n.Value == value
+		}
+	}
+
+	// Handlers registered on the root are not inherited by the
+	// sub-tree rooted at node (2), and vice versa.
+	root.AddSkipNodeFunc(skipValue(4))
+	two.AddSkipNodeFunc(skipValue(5))
+
+	tests := []struct {
+		node *binarytree.Node[int]
+		want []int
+	}{
+		{node: root, want: []int{1, 2, 5, 3}},
+		{node: two, want: []int{2, 4}},
+	}
+
+	for _, test := range tests {
+		if got := test.node.ToSlice(binarytree.PreOrder); !reflect.DeepEqual(got, test.want) {
+			t.Fatalf("want pre-order values %v, got %v", test.want, got)
+		}
+	}
+
+	// Handlers apply to the starting node itself
+	two.AddSkipNodeFunc(skipValue(2))
+	if got := two.ToSlice(binarytree.PreOrder); len(got) != 0 {
+		t.Fatalf("want no values, got %v", got)
+	}
+}
+
+func TestFindNode(t *testing.T) {
+	// Construct the following simple binary tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(2)
+	root.InsertRight(3)
 	two.InsertLeft(4)
 	two.InsertRight(5)
-	three := root.InsertRight(3)
-	three.InsertLeft(6)
 
-	if root.IsPerfectTree() {
-		t.Fatal("tree should not be perfect")
+	goodPredicate := func(n *binarytree.Node[int]) bool {
+		if n.Value == 2 {
+			return true
+		}
+		return false
+	}
+
+	node, ok := root.FindNode(goodPredicate)
+	if !ok {
+		t.Fatal("unable to find node (2)")
+	}
+
+	// The node we are looking for should have left and right
+	// children
+	if node.Left == nil || node.Right == nil {
+		t.Fatal("node (2) does not have left or right children")
+	}
+
+	// No node will match is supposed to match with this predicate
+	badPredicate := func(n *binarytree.Node[int]) bool {
+		return false
+	}
+
+	if _, ok := root.FindNode(badPredicate); ok {
+		t.Fatal("no node is supposed to match the predicate")
+	}
+}
+
+func TestFindAll(t *testing.T) {
+	// Construct the following simple binary tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	odd := func(n *binarytree.Node[int]) bool {
+		return n.Value%2 == 1
+	}
+
+	values := make([]int, 0)
+	for _, node := range root.FindAll(odd) {
+		values = append(values, node.Value)
+	}
+
+	wantValues := []int{1, 5, 3}
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Fatalf("want found values %v, got %v", wantValues, values)
+	}
+
+	none := func(n *binarytree.Node[int]) bool {
+		return false
+	}
+	if got := root.FindAll(none); got == nil || len(got) != 0 {
+		t.Fatal("want empty slice of nodes")
+	}
+
+	// Skip the sub-tree at node (2)
+	root.AddSkipNodeFunc(func(n *binarytree.Node[int]) bool {
+		return n.Value == 2
+	})
+
+	values = make([]int, 0)
+	for _, node := range root.FindAll(odd) {
+		values = append(values, node.Value)
+	}
+
+	wantValues = []int{1, 3}
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Fatalf("want found values %v, got %v", wantValues, values)
+	}
+}
+
+func TestIsFullTree(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(2)
+	root.InsertRight(3)
+	two.InsertLeft(4)
+	two.InsertRight(5)
+
+	if !root.IsFullTree() {
+		t.Fatal("tree should be full")
+	}
+}
+
+func TestIsNotFullTree(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /
+	//   2
+	//  / \
+	// 4   5
+	//
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(2)
+	two.InsertLeft(4)
+	two.InsertRight(5)
+
+	if root.IsFullTree() {
+		t.Fatal("tree should not be full")
+	}
+}
+
+func TestTreeIsDegenerateTree(t *testing.T) {
+	// Our test tree
+	//
+	//     1
+	//    /
+	//   2
+	//    \
+	//     3
+	//    /
+	//   4
+	//
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(2)
+	three := two.InsertRight(3)
+	three.InsertLeft(4)
+
+	if !root.IsDegenerateTree() {
+		t.Fatal("tree should be degenerate")
+	}
+}
+
+func TestTreeIsNotDegenerate(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /
+	//   2
+	//  / \
+	// 4   5
+	//
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(2)
+	two.InsertLeft(4)
+	two.InsertRight(5)
+
+	if root.IsDegenerateTree() {
+		t.Fatal("tree should not be degenerate")
+	}
+}
+
+func TestIsBalancedTree(t *testing.T) {
+	// Unbalanced tree
+	//
+	//     1
+	//    /
+	//   2
+	//  /
+	// 3
+	//
+	unbalanced_root := binarytree.NewNode(1)
+	two := unbalanced_root.InsertLeft(2)
+	two.InsertLeft(3)
+
+	if unbalanced_root.IsBalancedTree() {
+		t.Fatal("tree should not be balanced")
+	}
+
+	// Another unbalanced tree
+	//
+	//         __1
+	//        /   \
+	//     __2     3
+	//    /   \
+	//   4     5
+	//  / \
+	// 6   7
+	unbalanced_root = binarytree.NewNode(1)
+	unbalanced_root.InsertRight(3)
+	two = unbalanced_root.InsertLeft(2)
+	two.InsertRight(5)
+	four := two.InsertLeft(4)
+	four.InsertLeft(6)
+	four.InsertRight(7)
+
+	if unbalanced_root.IsBalancedTree() {
+		t.Fatal("tree should not be balanced")
+	}
+
+	// A single root node is a balanced tree
+	leaf := binarytree.NewNode(1)
+	if !leaf.IsBalancedTree() {
+		t.Fatal("single leaf node should be balanced")
+	}
+
+	// Yet another unbalanced tree
+	//
+	//     __1
+	//    /
+	//   2
+	//  / \
+	// 4   5
+	//
+	unbalanced_root = binarytree.NewNode(1)
+	two = unbalanced_root.InsertLeft(2)
+	two.InsertLeft(4)
+	two.InsertRight(5)
+
+	if unbalanced_root.IsBalancedTree() {
+		t.Fatal("tree should not be balanced")
+	}
+
+	// The sub-tree with root node (2) is balanced
+	if !two.IsBalancedTree() {
+		t.Fatal("tree with root (2) should be balanced")
+	}
+
+	// A balanced tree
+	//
+	//   1__
+	//  /   \
+	// 2     3
+	//      / \
+	//     4   5
+	balanced_root := binarytree.NewNode(1)
+	balanced_root.InsertLeft(2)
+	three := balanced_root.InsertRight(3)
+	three.InsertLeft(4)
+	three.InsertRight(5)
+
+	if !balanced_root.IsBalancedTree() {
+		t.Fatal("tree should be balanced")
+	}
+}
+
+func TestIsCompleteTree(t *testing.T) {
+	// A complete binary tree
+	//
+	//    1
+	//   / \
+	//  2   3
+	//
+	root := binarytree.NewNode(1)
+	root.InsertLeft(2)
+	root.InsertRight(3)
+
+	if !root.IsCompleteTree() {
+		t.Fatal("tree should be complete")
+	}
+
+	// A complete binary tree
+	//
+	//      1
+	//     / \
+	//    2   3
+	//   /
+	//  4
+	//
+	root = binarytree.NewNode(1)
+	root.InsertRight(3)
+	two := root.InsertLeft(2)
+	two.InsertLeft(4)
+
+	if !root.IsCompleteTree() {
+		t.Fatal("tree should be complete")
+	}
+
+	// A complete binary tree
+	//
+	//     __1__
+	//    /     \
+	//   2       3
+	//  / \     /
+	// 4   5   6
+	//
+	root = binarytree.NewNode(1)
+	two = root.InsertLeft(2)
+	two.InsertLeft(4)
+	two.InsertRight(5)
+	three := root.InsertRight(3)
+	three.InsertLeft(6)
+
+	if !root.IsCompleteTree() {
+		t.Fatal("tree should be complete")
+	}
+
+	// Not complete binary tree
+	//
+	//     __1_
+	//    /    \
+	//   2      3
+	//  / \      \
+	// 4   5      6
+	//
+	root = binarytree.NewNode(1)
+	two = root.InsertLeft(2)
+	two.InsertLeft(4)
+	two.InsertRight(5)
+	three = root.InsertRight(3)
+	three.InsertRight(6)
+
+	if root.IsCompleteTree() {
+		t.Fatal("tree should not be complete")
+	}
+
+	// Not complete binary tree
+	//
+	//     1
+	//    /
+	//   2
+	//  /
+	// 3
+	//
+	root = binarytree.NewNode(1)
+	two = root.InsertLeft(2)
+	two.InsertLeft(3)
+
+	if root.IsCompleteTree() {
+		t.Fatal("tree should not be complete")
+	}
+
+	// Not complete binary tree
+	//
+	//   __1__
+	//  /     \
+	// 2       3
+	//  \     / \
+	//   4   5   6
+	root = binarytree.NewNode(1)
+	two = root.InsertLeft(2)
+	two.InsertRight(4)
+	three = root.InsertRight(3)
+	three.InsertLeft(5)
+	three.InsertRight(6)
+
+	if root.IsCompleteTree() {
+		t.Fatal("tree should not be complete")
+	}
+
+	// Not complete binary tree
+	//
+	//   1__
+	//  /   \
+	// 2     3
+	//      / \
+	//     4   5
+	root = binarytree.NewNode(1)
+	root.InsertLeft(2)
+	three = root.InsertRight(3)
+	three.InsertLeft(4)
+	three.InsertRight(5)
+
+	if root.IsCompleteTree() {
+		t.Fatal("tree should not be complete")
+	}
+
+	// A complete binary tree with a single root node
+	root = binarytree.NewNode(1)
+	if !root.IsCompleteTree() {
+		t.Fatal("tree should be complete")
+	}
+}
+
+func TestIsHeapShaped(t *testing.T) {
+	// Heap shaped trees
+	//
+	//    1       1         __1__
+	//   / \     / \       /     \
+	//  2   3   2   3     2       3
+	//         /         / \     /
+	//        4         4   5   6
+	//
+	shaped := []*binarytree.Node[int]{
+		binarytree.NewNode(1),
+		binarytree.Build(1).Left(2).Up().Right(3).Root(),
+		binarytree.Build(1).Left(2).Left(4).Up().Up().Right(3).Root(),
+		binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Left(6).Root(),
+	}
+
+	for _, root := range shaped {
+		if !root.IsHeapShaped() || !root.IsCompleteTree() {
+			t.Fatal("tree should be heap shaped")
+		}
+	}
+
+	// Trees which are not heap shaped
+	//
+	//     1       1__           __1__
+	//    /       /   \         /     \
+	//   2       2     3       2       3
+	//  /             / \     / \       \
+	// 3             4   5   4   5       6
+	//
+	notShaped := []*binarytree.Node[int]{
+		binarytree.Build(1).Left(2).Left(3).Root(),
+		binarytree.Build(1).Left(2).Up().Right(3).Left(4).Up().Right(5).Root(),
+		binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Right(6).Root(),
+	}
+
+	for _, root := range notShaped {
+		if root.IsHeapShaped() || root.IsCompleteTree() {
+			t.Fatal("tree should not be heap shaped")
+		}
+	}
+}
+
+func TestIsMinMaxHeap(t *testing.T) {
+	// A min-heap
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	if !root.IsMinHeap(binarytree.IntComparator) {
+		t.Fatal("tree should be a min-heap")
+	}
+
+	if root.IsMaxHeap(binarytree.IntComparator) {
+		t.Fatal("tree should not be a max-heap")
+	}
+
+	// A max-heap
+	//
+	//     __9
+	//    /   \
+	//   7     8
+	//  / \
+	// 3   7
+	//
+	root = binarytree.Build(9).Left(7).Left(3).Up().Right(7).Up().Up().Right(8).Root()
+	if !root.IsMaxHeap(binarytree.IntComparator) {
+		t.Fatal("tree should be a max-heap")
+	}
+
+	if root.IsMinHeap(binarytree.IntComparator) {
+		t.Fatal("tree should not be a min-heap")
+	}
+
+	// Heap ordered, but not heap shaped
+	//
+	//   1
+	//    \
+	//     2
+	//
+	root = binarytree.Build(1).Right(2).Root()
+	if root.IsMinHeap(binarytree.IntComparator) {
+		t.Fatal("tree which is not heap shaped should not be a min-heap")
+	}
+}
+
+func TestIsPerfectTree(t *testing.T) {
+	// A perfect binary tree
+	//
+	//    1
+	//   / \
+	//  2   3
+	//
+	root := binarytree.NewNode(1)
+	root.InsertLeft(2)
+	root.InsertRight(3)
+
+	if !root.IsPerfectTree() {
+		t.Fatal("tree should be perfect")
+	}
+
+	// A non-perfect binary tree
+	//
+	//      1
+	//     / \
+	//    2   3
+	//   /
+	//  4
+	//
+	root = binarytree.NewNode(1)
+	root.InsertRight(3)
+	two := root.InsertLeft(2)
+	two.InsertLeft(4)
+
+	if root.IsPerfectTree() {
+		t.Fatal("tree should not be perfect")
+	}
+
+	// A non-perfect binary tree
+	//
+	//     __1__
+	//    /     \
+	//   2       3
+	//  / \     /
+	// 4   5   6
+	//
+	root = binarytree.NewNode(1)
+	two = root.InsertLeft(2)
+	two.InsertLeft(4)
+	two.InsertRight(5)
+	three := root.InsertRight(3)
+	three.InsertLeft(6)
+
+	if root.IsPerfectTree() {
+		t.Fatal("tree should not be perfect")
+	}
+
+	// A perfect binary tree
+	//
+	//     __1__
+	//    /     \
+	//   2       3
+	//  / \     / \
+	// 4   5   6   7
+	//
+	root = binarytree.NewNode(1)
+	two = root.InsertLeft(2)
+	two.InsertLeft(4)
+	two.InsertRight(5)
+	three = root.InsertRight(3)
+	three.InsertLeft(6)
+	three.InsertRight(7)
+
+	if !root.IsPerfectTree() {
+		t.Fatal("tree should be perfect")
+	}
+
+	// A non-perfect binary tree
+	//
+	//     1
+	//    /
+	//   2
+	//  /
+	// 3
+	//
+	root = binarytree.NewNode(1)
+	two = root.InsertLeft(2)
+	two.InsertLeft(3)
+
+	if root.IsPerfectTree() {
+		t.Fatal("tree should not be perfect")
+	}
+
+	// A non-perfect binary tree
+	//
+	//   1__
+	//  /   \
+	// 2     3
+	//      / \
+	//     4   5
+	root = binarytree.NewNode(1)
+	root.InsertLeft(2)
+	three = root.InsertRight(3)
+	three.InsertLeft(4)
+	three.InsertRight(5)
+
+	if root.IsPerfectTree() {
+		t.Fatal("tree should not be perfect")
+	}
+
+	// A perfect binary tree with a single root node
+	root = binarytree.NewNode(1)
+	if !root.IsPerfectTree() {
+		t.Fatal("tree should be perfect")
+	}
+}
+
+func TestIsBinarySearchTree(t *testing.T) {
+	// A valid BST
+	//
+	//    2
+	//   / \
+	//  1   3
+	//
+	root := binarytree.NewNode(2)
+	root.InsertLeft(1)
+	root.InsertRight(3)
+
+	if !root.IsBinarySearchTree(binarytree.IntComparator) {
+		t.Fatal("tree should be BST")
+	}
+
+	// Invalid BST
+	//
+	//    1
+	//   / \
+	//  2   3
+	//
+	root = binarytree.NewNode(1)
+	root.InsertLeft(2)
+	root.InsertRight(3)
+
+	if root.IsBinarySearchTree(binarytree.IntComparator) {
+		t.Fatal("tree should not be BST")
+	}
+
+	// Invalid BST
+	//
+	//      1
+	//     / \
+	//    2   3
+	//   /
+	//  4
+	//
+	root = binarytree.NewNode(1)
+	root.InsertRight(3)
+	two := root.InsertLeft(2)
+	two.InsertLeft(4)
+
+	if root.IsBinarySearchTree(binarytree.IntComparator) {
+		t.Fatal("tree should not be BST")
+	}
+
+	// A valid BST
+	//
+	//     ______8
+	//    /       \
+	//   3__       10___
+	//  /   \           \
+	// 1     6          _14
+	//      / \        /
+	//     4   7      13
+	//
+	root = binarytree.NewNode(8)
+	three := root.InsertLeft(3)
+	three.InsertLeft(1)
+	six := three.InsertRight(6)
+	six.InsertLeft(4)
+	six.InsertRight(7)
+	ten := root.InsertRight(10)
+	fourteen := ten.InsertRight(14)
+	fourteen.InsertLeft(13)
+
+	if !root.IsBinarySearchTree(binarytree.IntComparator) {
+		t.Fatal("tree should be BST")
+	}
+
+	// A tree with a single root node is a valid BST
+	root = binarytree.NewNode(1)
+	if !root.IsBinarySearchTree(binarytree.IntComparator) {
+		t.Fatal("tree should be BST")
+	}
+
+	// A valid BST
+	//
+	//   B
+	//  / \
+	// A   C
+	//
+	str_root := binarytree.NewNode("B")
+	str_root.InsertLeft("A")
+	str_root.InsertRight("C")
+
+	if !str_root.IsBinarySearchTree(binarytree.StringComparator) {
+		t.Fatal("tree should be BST")
+	}
+
+	// Invalid BST
+	//
+	//     A
+	//    / \
+	//   B   C
+	//  /
+	// D
+	str_root = binarytree.NewNode("A")
+	str_root.InsertRight("C")
+	b := str_root.InsertLeft("B")
+	b.InsertLeft("D")
+
+	if str_root.IsBinarySearchTree(binarytree.StringComparator) {
+		t.Fatal("tree should not be BST")
+	}
+}
+
+func TestNodeAttributes(t *testing.T) {
+	root := binarytree.NewNode(1)
+
+	if root.GetDotAttributes() != "" {
+		t.Fatal("node is expected to have no attributes")
+	}
+
+	root.AddAttribute("color", "green")
+
+	wantAttrs := "color=green"
+	gotAttrs := root.GetDotAttributes()
+	if gotAttrs != wantAttrs {
+		t.Fatal("node attributes mismatch")
+	}
+
+	// Attributes are sorted by name
+	root.AddAttribute("shape", "box")
+	wantAttrs = "color=green shape=box"
+	if gotAttrs := root.GetDotAttributes(); gotAttrs != wantAttrs {
+		t.Fatalf("want node attributes %q, got %q", wantAttrs, gotAttrs)
+	}
+
+	root.AddAttribute("style", "filled")
+	root.AddAttribute("fillcolor", "red")
+
+	wantAttrs = "color=green fillcolor=red shape=box style=filled"
+	for i := 0; i < 10; i++ {
+		if gotAttrs := root.GetDotAttributes(); gotAttrs != wantAttrs {
+			t.Fatalf("want node attributes %q, got %q", wantAttrs, gotAttrs)
+		}
+	}
+}
+
+func TestSetAttributeWhere(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	leaves := func(n *binarytree.Node[int]) bool {
+		return n.IsLeafNode()
+	}
+
+	if got := root.SetAttributeWhere("color", "red", leaves); got != 3 {
+		t.Fatalf("want 3 updated nodes, got %d", got)
+	}
+
+	root.WalkPreOrder(func(node *binarytree.Node[int]) error {
+		if node.HasAttribute("color") != node.IsLeafNode() {
+			t.Fatalf("unexpected color attribute on node (%d)", node.Value)
+		}
+		return nil
+	})
+
+	// Sub-trees of the skipped nodes are not examined
+	root.AddSkipNodeFunc(func(n *binarytree.Node[int]) bool {
+		return n.Value == 2
+	})
+	if got := root.SetAttributeWhere("shape", "box", leaves); got != 1 {
+		t.Fatalf("want 1 updated node, got %d", got)
+	}
+
+	if !root.Right.HasAttribute("shape") || root.Left.Left.HasAttribute("shape") {
+		t.Fatal("only node (3) should have the shape attribute")
+	}
+}
+
+func TestGetAttribute(t *testing.T) {
+	root := binarytree.NewNode(1)
+	root.AddAttribute("tooltip", `say "hi"`)
+
+	// The stored value is returned as is, without Dot quoting
+	value, ok := root.GetAttribute("tooltip")
+	if !ok || value != `say "hi"` {
+		t.Fatalf("want tooltip attribute %q, got %q", `say "hi"`, value)
+	}
+
+	if value, ok := root.GetAttribute("color"); ok || value != "" {
+		t.Fatalf("want missing color attribute, got %q", value)
+	}
+}
+
+func TestRemoveAttributes(t *testing.T) {
+	root := binarytree.NewNode(1)
+	root.AddAttribute("color", "green")
+	root.AddAttribute("shape", "box")
+
+	if !root.HasAttribute("color") || root.HasAttribute("style") {
+		t.Fatal("node should have only the color and shape attributes")
+	}
+
+	root.RemoveAttribute("color")
+	root.RemoveAttribute("style")
+	if root.HasAttribute("color") {
+		t.Fatal("color attribute should be removed")
+	}
+
+	wantAttrs := "shape=box"
+	if gotAttrs := root.GetDotAttributes(); gotAttrs != wantAttrs {
+		t.Fatalf("want node attributes %q, got %q", wantAttrs, gotAttrs)
+	}
+
+	root.ClearAttributes()
+	if gotAttrs := root.GetDotAttributes(); gotAttrs != "" {
+		t.Fatalf("want no node attributes, got %q", gotAttrs)
+	}
+
+	// Attributes can be added again after clearing them
+	root.AddAttribute("color", "red")
+	if !root.HasAttribute("color") {
+		t.Fatal("color attribute should be present")
+	}
+}
+
+func TestWriteDot(t *testing.T) {
+	// Our test tree
+	//
+	//   1__
+	//  /   \
+	// 2     3
+	//      / \
+	//     4   5
+	root := binarytree.NewNode(1)
+	root.InsertLeft(2)
+	three := root.InsertRight(3)
+	three.InsertLeft(4)
+	three.InsertRight(5)
+
+	var buf bytes.Buffer
+	if err := root.WriteDot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	if output == "" {
+		t.Fatal("got empty dot representation")
+	}
+
+	if !strings.HasPrefix(output, "digraph {") {
+		t.Fatal("missing dot prefix")
+	}
+
+	if !strings.HasSuffix(output, "}\n") {
+		t.Fatal("missing dot suffix")
+	}
+}
+
+func TestWriteDotGolden(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	root.Left.AddAttribute("color", "green")
+
+	want := `digraph {
+	node [color=lightblue fillcolor=lightblue fontcolor=black shape=record style="filled, rounded"]
+	1 [label="<l>|<v> 1|<r>" ]
+	1:l -> 2:v
+	1:r -> 3:v
+	2 [label="<l>|<v> 2|<r>" color=green]
+	2:l -> 4:v
+	2:r -> 5:v
+	4 [label="<l>|<v> 4|<r>" ]
+	5 [label="<l>|<v> 5|<r>" ]
+	3 [label="<l>|<v> 3|<r>" ]
+}
+`
+
+	for i := 0; i < 3; i++ {
+		var buf bytes.Buffer
+		if err := root.WriteDot(&buf); err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.String() != want {
+			t.Fatalf("want dot output:\n%s\ngot:\n%s", want, buf.String())
+		}
+	}
+
+	// Node ids are unique across the trees of a forest
+	var buf bytes.Buffer
+	roots := []*binarytree.Node[int]{binarytree.NewNode(1), binarytree.NewNode(1)}
+	if err := binarytree.WriteDotForest(&buf, roots); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"\t1 [label=", "\t2 [label="} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("missing %q in dot forest output", want)
+		}
+	}
+}
+
+func TestWriteDotEscaping(t *testing.T) {
+	root := binarytree.NewNode(`he said "hi"`)
+	root.InsertLeft("back\\slash\nnewline")
+	root.AddAttribute("tooltip", `say "hi"`)
+	root.AddAttribute("color", "green")
+	root.AddAttribute("label", "<<b>bold</b>>")
+	root.AddAttribute("xlabel", "<b>x</b> and <i>")
+
+	var buf bytes.Buffer
+	if err := root.WriteDot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		`<v> he said \"hi\"|`,
+		`<v> back\\slash\nnewline|`,
+		`tooltip="say \"hi\""`,
+		`color=green`,
+		`label=<<b>bold</b>>`,
+		`xlabel="<b>x</b> and <i>"`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("missing %q in dot output:\n%s", want, output)
+		}
+	}
+
+	// The escaped label can be unquoted back to the original value
+	label := regexp.MustCompile(`<v> (.*)\|<r>"`).FindStringSubmatch(output)
+	if label == nil {
+		t.Fatal("missing label of root node")
+	}
+
+	got, err := strconv.Unquote(`"` + label[1] + `"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != root.Value {
+		t.Fatalf("want round-tripped label %q, got %q", root.Value, got)
+	}
+
+	// Each node is emitted on a single line
+	if got := strings.Count(output, "\n"); got != 6 {
+		t.Fatalf("want 6 lines of dot output, got %d", got)
+	}
+
+	// Record field separators and delimiters are escaped, so that
+	// the value is rendered as a single field
+	buf.Reset()
+	if err := binarytree.NewNode("a|b<c>{d}").WriteDot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `[label="<l>|<v> a\|b\<c\>\{d\}|<r>" ]`
+	if output := buf.String(); !strings.Contains(output, want) {
+		t.Fatalf("missing %q in dot output:\n%s", want, output)
+	}
+
+	// Plain labels are not records
+	buf.Reset()
+	opts := binarytree.DotOptions{Plain: true}
+	if err := binarytree.NewNode("a|b").WriteDotWithOptions(&buf, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if output := buf.String(); !strings.Contains(output, `[label="a|b" ]`) {
+		t.Fatalf("plain label should not be escaped:\n%s", output)
+	}
+}
+
+func TestWriteDotWithLabeler(t *testing.T) {
+	type person struct {
+		Name string
+		Age  int
+	}
+
+	// Our test tree
+	//
+	//      alice
+	//     /     \
+	//   bob    "carol"
+	//
+	root := binarytree.NewNode(person{Name: "alice", Age: 30})
+	root.InsertLeft(person{Name: "bob", Age: 25})
+	root.InsertRight(person{Name: `"carol"`, Age: 35})
+
+	label := func(node *binarytree.Node[person]) string {
+		return node.Value.Name
+	}
+
+	var buf bytes.Buffer
+	if err := root.WriteDotWithLabeler(&buf, label); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the custom labels are used
+	labels := make([]string, 0)
+	pattern := regexp.MustCompile(`label="<l>\|<v> (.*)\|<r>"`)
+	for _, match := range pattern.FindAllStringSubmatch(buf.String(), -1) {
+		labels = append(labels, match[1])
+	}
+	slices.Sort(labels)
+
+	wantLabels := []string{`\"carol\"`, "alice", "bob"}
+	if !reflect.DeepEqual(labels, wantLabels) {
+		t.Fatalf("want labels %v, got %v", wantLabels, labels)
+	}
+
+	// WriteDot uses the default labels
+	buf.Reset()
+	if err := root.WriteDot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), `<v> \{alice 30\}|`) {
+		t.Fatal("missing default label in dot output")
+	}
+}
+
+func TestFlipEquiv(t *testing.T) {
+	// Our test trees
+	//
+	//     __1          1__
+	//    /   \        /   \
+	//   2     3      3     2
+	//  / \                / \
+	// 4   5              5   4
+	//
+	a := binarytree.NewNode(1)
+	a.InsertRight(3)
+	aTwo := a.InsertLeft(2)
+	aTwo.InsertLeft(4)
+	aTwo.InsertRight(5)
+
+	b := binarytree.NewNode(1)
+	b.InsertLeft(3)
+	bTwo := b.InsertRight(2)
+	bTwo.InsertLeft(5)
+	bTwo.InsertRight(4)
+
+	eq := func(x, y int) bool { return x == y }
+	if !binarytree.FlipEquiv(a, b, eq) {
+		t.Fatal("trees should be flip equivalent")
+	}
+
+	if binarytree.Equal(a, b) {
+		t.Fatal("trees should not be equal")
+	}
+
+	// Moving node (4) under node (3) breaks the equivalence
+	bTwo.Right = nil
+	b.Left.InsertLeft(4)
+	if binarytree.FlipEquiv(a, b, eq) {
+		t.Fatal("trees should not be flip equivalent")
+	}
+
+	if !binarytree.FlipEquiv[int](nil, nil, eq) {
+		t.Fatal("empty trees should be flip equivalent")
+	}
+}
+
+func TestCountSubtreesWhere(t *testing.T) {
+	// Our test tree
+	//
+	//      __1__
+	//     /     \
+	//   -2       3
+	//   / \     / \
+	//  4   5   6   7
+	//
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(-2)
+	two.InsertLeft(4)
+	two.InsertRight(5)
+	three := root.InsertRight(3)
+	three.InsertLeft(6)
+	three.InsertRight(7)
+
+	positive := func(n *binarytree.Node[int]) bool {
+		return n.Value > 0
+	}
+
+	// Subtrees at (4), (5), (6), (7) and (3)
+	if got := root.CountSubtreesWhere(positive); got != 5 {
+		t.Fatalf("want 5 all-positive subtrees, got %d", got)
+	}
+
+	// Every subtree matches
+	always := func(n *binarytree.Node[int]) bool {
+		return true
+	}
+	if got := root.CountSubtreesWhere(always); got != 7 {
+		t.Fatalf("want 7 subtrees, got %d", got)
+	}
+}
+
+func TestMaxAncestorDescendantDiff(t *testing.T) {
+	// Our test tree
+	//
+	//      ____8
+	//     /     \
+	//    3__     10__
+	//   /   \        \
+	//  1     6        14
+	//       / \      /
+	//      4   7    13
+	//
+	root := binarytree.NewNode(8)
+	three := root.InsertLeft(3)
+	three.InsertLeft(1)
+	six := three.InsertRight(6)
+	six.InsertLeft(4)
+	six.InsertRight(7)
+	ten := root.InsertRight(10)
+	fourteen := ten.InsertRight(14)
+	fourteen.InsertLeft(13)
+
+	// The max difference is between nodes (8) and (1)
+	if got := binarytree.MaxAncestorDescendantDiff(root); got != 7 {
+		t.Fatalf("want max difference 7, got %d", got)
+	}
+
+	// Within the sub-tree of node (10) the max difference is
+	// between nodes (10) and (14)
+	if got := binarytree.MaxAncestorDescendantDiff(ten); got != 4 {
+		t.Fatalf("want max difference 4, got %d", got)
+	}
+
+	// A single node has no descendants
+	leaf := binarytree.NewNode(1.5)
+	if got := binarytree.MaxAncestorDescendantDiff(leaf); got != 0 {
+		t.Fatalf("want max difference 0, got %f", got)
+	}
+}
+
+func TestWriteDotForest(t *testing.T) {
+	// Our test trees
+	//
+	//    1       10
+	//   / \     /
+	//  2   3   20
+	//
+	first := binarytree.NewNode(1)
+	first.InsertLeft(2)
+	first.InsertRight(3)
+
+	second := binarytree.NewNode(10)
+	second.InsertLeft(20)
+
+	var buf bytes.Buffer
+	roots := []*binarytree.Node[int]{first, second}
+	if err := binarytree.WriteDotForest(&buf, roots); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	if !strings.HasPrefix(output, "digraph {") {
+		t.Fatal("missing dot prefix")
+	}
+
+	if !strings.HasSuffix(output, "}\n") {
+		t.Fatal("missing dot suffix")
+	}
+
+	if strings.Count(output, "digraph") != 1 {
+		t.Fatal("expected a single digraph")
+	}
+
+	for _, label := range []string{"<v> 1|", "<v> 10|"} {
+		if !strings.Contains(output, label) {
+			t.Fatalf("missing root node %q in dot output", label)
+		}
+	}
+
+	if got := strings.Count(output, "->"); got != 3 {
+		t.Fatalf("want 3 edges, got %d", got)
+	}
+}
+
+func TestWeightedDepthSum(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(2)
+	root.InsertRight(3)
+	two.InsertLeft(4)
+	two.InsertRight(5)
+
+	// 1*0 + (2+3)*1 + (4+5)*2
+	if got := binarytree.WeightedDepthSum(root); got != 23 {
+		t.Fatalf("want weighted depth sum 23, got %d", got)
+	}
+
+	// Depths are relative to the given root: 2*0 + (4+5)*1
+	if got := binarytree.WeightedDepthSum(two); got != 9 {
+		t.Fatalf("want weighted depth sum 9, got %d", got)
+	}
+}
+
+func TestHasPathSum(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	tests := []struct {
+		target int
+		want   bool
+	}{
+		{target: 7, want: true},  // 1 -> 2 -> 4
+		{target: 8, want: true},  // 1 -> 2 -> 5
+		{target: 4, want: true},  // 1 -> 3
+		{target: 3, want: false}, // 1 -> 2 is a partial path
+		{target: 1, want: false}, // 1 is a partial path
+		{target: 10, want: false},
+	}
+
+	for _, test := range tests {
+		if got := binarytree.HasPathSum(root, test.target); got != test.want {
+			t.Fatalf("want %t for target %d, got %t", test.want, test.target, got)
+		}
+	}
+
+	if binarytree.HasPathSum[int](nil, 0) {
+		t.Fatal("empty tree should have no paths")
+	}
+
+	floats := binarytree.Build(1.5).Left(-0.5).Root()
+	if !binarytree.HasPathSum(floats, 1.0) {
+		t.Fatal("want path sum 1.0 in float tree")
+	}
+}
+
+func TestVerifyRoundTrip(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(2)
+	root.InsertRight(3)
+	two.InsertLeft(4)
+	two.InsertRight(5)
+
+	type jsonNode struct {
+		Value int       `json:"value"`
+		Left  *jsonNode `json:"left"`
+		Right *jsonNode `json:"right"`
+	}
+
+	var toNode func(j *jsonNode) *binarytree.Node[int]
+	toNode = func(j *jsonNode) *binarytree.Node[int] {
+		if j == nil {
+			return nil
+		}
+		node := binarytree.NewNode(j.Value)
+		node.Left = toNode(j.Left)
+		node.Right = toNode(j.Right)
+		return node
+	}
+
+	marshal := func(n *binarytree.Node[int]) ([]byte, error) {
+		return n.MarshalJSONWithOptions()
+	}
+	unmarshal := func(data []byte) (*binarytree.Node[int], error) {
+		var j jsonNode
+		if err := json.Unmarshal(data, &j); err != nil {
+			return nil, err
+		}
+		return toNode(&j), nil
+	}
+	eq := func(a, b int) bool { return a == b }
+
+	if err := root.VerifyRoundTrip(marshal, unmarshal, eq); err != nil {
+		t.Fatal(err)
+	}
+
+	// A lossy codec, which drops the right sub-tree of the root
+	lossyUnmarshal := func(data []byte) (*binarytree.Node[int], error) {
+		node, err := unmarshal(data)
+		if err != nil {
+			return nil, err
+		}
+		node.Right = nil
+		return node, nil
+	}
+
+	err := root.VerifyRoundTrip(marshal, lossyUnmarshal, eq)
+	if !errors.Is(err, binarytree.ErrRoundTripMismatch) {
+		t.Fatalf("want round-trip mismatch error, got %v", err)
+	}
+
+	// Errors from the codec are propagated
+	badUnmarshal := func(data []byte) (*binarytree.Node[int], error) {
+		return nil, errors.New("boom")
+	}
+	if err := root.VerifyRoundTrip(marshal, badUnmarshal, eq); err == nil {
+		t.Fatal("want unmarshal error")
+	}
+}
+
+func TestNodesAtDistance(t *testing.T) {
+	// Our test tree
+	//
+	//     __1__
+	//    /     \
+	//   2       3
+	//  / \     / \
+	// 4   5   6   7
+	//
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(2)
+	two.InsertLeft(4)
+	two.InsertRight(5)
+	three := root.InsertRight(3)
+	three.InsertLeft(6)
+	three.InsertRight(7)
+
+	values := func(nodes []*binarytree.Node[int]) []int {
+		result := make([]int, 0)
+		for _, node := range nodes {
+			result = append(result, node.Value)
+		}
+		return result
+	}
+
+	tests := []struct {
+		k    int
+		want []int
+	}{
+		{k: 0, want: []int{2}},
+		{k: 1, want: []int{4, 5, 1}},
+		{k: 2, want: []int{3}},
+		{k: 3, want: []int{6, 7}},
+		{k: 4, want: []int{}},
+		{k: -1, want: []int{}},
+	}
+
+	for _, test := range tests {
+		got := values(root.NodesAtDistance(two, test.k))
+		if !reflect.DeepEqual(got, test.want) {
+			t.Fatalf("want nodes %v at distance %d, got %v", test.want, test.k, got)
+		}
+	}
+
+	// A target outside of the tree
+	other := binarytree.NewNode(1)
+	if got := root.NodesAtDistance(other, 1); len(got) != 0 {
+		t.Fatalf("want no nodes, got %v", values(got))
+	}
+}
+
+func TestLeafSimilar(t *testing.T) {
+	// Our test trees
+	//
+	//     __1          __9
+	//    /   \        /   \
+	//   2     3      4     8
+	//  / \                / \
+	// 4   5              5   3
+	//
+	a := binarytree.NewNode(1)
+	two := a.InsertLeft(2)
+	a.InsertRight(3)
+	two.InsertLeft(4)
+	two.InsertRight(5)
+
+	b := binarytree.NewNode(9)
+	b.InsertLeft(4)
+	eight := b.InsertRight(8)
+	eight.InsertLeft(5)
+	eight.InsertRight(3)
+
+	eq := func(x, y int) bool { return x == y }
+	if !binarytree.LeafSimilar(a, b, eq) {
+		t.Fatal("trees should be leaf similar")
+	}
+
+	// Adding a leaf changes the sequence
+	eight.Right.InsertLeft(7)
+	if binarytree.LeafSimilar(a, b, eq) {
+		t.Fatal("trees should not be leaf similar")
+	}
+}
+
+func TestValueOr(t *testing.T) {
+	// Our test tree
+	//
+	//    1
+	//   /
+	//  2
+	//
+	root := binarytree.NewNode(1)
+	root.InsertLeft(2)
+
+	if got := root.Left.ValueOr(-1); got != 2 {
+		t.Fatalf("want value 2, got %d", got)
+	}
+
+	if got := root.Right.ValueOr(-1); got != -1 {
+		t.Fatalf("want default value -1, got %d", got)
+	}
+
+	var empty *binarytree.Node[int]
+	if got := empty.ValueOr(42); got != 42 {
+		t.Fatalf("want default value 42, got %d", got)
+	}
+}
+
+func TestIsBinarySearchTreePartialOrder(t *testing.T) {
+	// Points are ordered by dominance, i.e. a point is less than
+	// another one, if both of its coordinates are less or equal.
+	type point struct {
+		x, y int
+	}
+
+	comparator := func(a, b point) int {
+		switch {
+		case a == b:
+			return 0
+		case a.x <= b.x && a.y <= b.y:
+			return -1
+		case a.x >= b.x && a.y >= b.y:
+			return 1
+		default:
+			return binarytree.Incomparable
+		}
+	}
+
+	// A valid BST
+	//
+	//        (2,2)
+	//       /     \
+	//   (1,1)     (3,3)
+	//
+	root := binarytree.NewNode(point{2, 2})
+	root.InsertLeft(point{1, 1})
+	root.InsertRight(point{3, 3})
+
+	if !root.IsBinarySearchTree(comparator) {
+		t.Fatal("tree should be BST")
+	}
+
+	// Invalid BST with incomparable siblings, where the right
+	// child is also incomparable with its parent.
+	//
+	//        (2,2)
+	//       /     \
+	//   (1,1)     (3,0)
+	//
+	root = binarytree.NewNode(point{2, 2})
+	root.InsertLeft(point{1, 1})
+	root.InsertRight(point{3, 0})
+
+	if root.IsBinarySearchTree(comparator) {
+		t.Fatal("tree should not be BST")
+	}
+}
+
+func TestLongestIncreasingPath(t *testing.T) {
+	// Our test tree
+	//
+	//     1
+	//      \
+	//       3
+	//      / \
+	//     2   4
+	//          \
+	//           2
+	//            \
+	//             5
+	//
+	root := binarytree.NewNode(1)
+	three := root.InsertRight(3)
+	three.InsertLeft(2)
+	four := three.InsertRight(4)
+	two := four.InsertRight(2)
+	two.InsertRight(5)
+
+	less := func(a, b int) bool { return a < b }
+
+	// The increasing path is 1 -> 3 -> 4, which is interrupted
+	// by node (2)
+	if got := binarytree.LongestIncreasingPath(root, less); got != 3 {
+		t.Fatalf("want longest increasing path 3, got %d", got)
+	}
+
+	// Extending the path after the decrease makes it the longest
+	// one: 2 -> 5 -> 6 -> 7
+	five := two.Right
+	five.InsertRight(6).InsertRight(7)
+	if got := binarytree.LongestIncreasingPath(root, less); got != 4 {
+		t.Fatalf("want longest increasing path 4, got %d", got)
+	}
+
+	if got := binarytree.LongestIncreasingPath[int](nil, less); got != 0 {
+		t.Fatalf("want longest increasing path 0, got %d", got)
+	}
+}
+
+func TestCloneDeepTree(t *testing.T) {
+	// A left-skewed tree, which would overflow the stack when
+	// cloned recursively
+	const depth = 100000
+	root := binarytree.NewNode(0)
+	node := root
+	for i := 1; i < depth; i++ {
+		node = node.InsertLeft(i)
+	}
+
+	clone := root.Clone()
+
+	orig, copied := root, clone
+	for i := 0; i < depth; i++ {
+		if copied == nil {
+			t.Fatalf("cloned tree is missing node (%d)", i)
+		}
+
+		if copied == orig {
+			t.Fatalf("node (%d) is shared with the original tree", i)
+		}
+
+		if copied.Value != orig.Value || copied.Right != nil {
+			t.Fatalf("cloned node (%d) does not match the original", i)
+		}
+
+		orig, copied = orig.Left, copied.Left
+	}
+
+	if copied != nil {
+		t.Fatal("cloned tree has extra nodes")
+	}
+
+	if !binarytree.Equal(root, clone) {
+		t.Fatal("cloned tree should be equal to the original")
+	}
+
+	// Mutating the clone does not affect the original tree
+	clone.Left.Value = -1
+	if root.Left.Value != 1 {
+		t.Fatal("original tree should not be modified")
+	}
+}
+
+func TestWalkPruned(t *testing.T) {
+	// Our test tree
+	//
+	//     __1__
+	//    /     \
+	//   2       3
+	//  / \     / \
+	// 4   5   6   7
+	//
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(2)
+	two.InsertLeft(4)
+	two.InsertRight(5)
+	three := root.InsertRight(3)
+	three.InsertLeft(6)
+	three.InsertRight(7)
+
+	tests := []struct {
+		order binarytree.TraversalOrder
+		want  []int
+	}{
+		{order: binarytree.InOrder, want: []int{1, 6, 3, 7}},
+		{order: binarytree.PreOrder, want: []int{1, 3, 6, 7}},
+		{order: binarytree.PostOrder, want: []int{6, 7, 3, 1}},
+		{order: binarytree.LevelOrder, want: []int{1, 3, 6, 7}},
+	}
+
+	for _, test := range tests {
+		// Count how many times the prune predicate is called
+		// for descendants of the pruned node (2)
+		descended := 0
+		prune := func(n *binarytree.Node[int]) bool {
+			if n.Value == 4 || n.Value == 5 {
+				descended++
+			}
+			return n.Value == 2
+		}
+
+		result := make([]int, 0)
+		visit := func(n *binarytree.Node[int]) error {
+			result = append(result, n.Value)
+			return nil
+		}
+
+		if err := root.WalkPruned(test.order, prune, visit); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(result, test.want) {
+			t.Fatalf("want values %v in order %d, got %v", test.want, test.order, result)
+		}
+
+		if descended != 0 {
+			t.Fatalf("descendants of pruned node were traversed in order %d", test.order)
+		}
+	}
+
+	visit := func(n *binarytree.Node[int]) error { return nil }
+	prune := func(n *binarytree.Node[int]) bool { return false }
+	err := root.WalkPruned(binarytree.TraversalOrder(42), prune, visit)
+	if !errors.Is(err, binarytree.ErrInvalidTraversalOrder) {
+		t.Fatalf("want invalid traversal order error, got %v", err)
+	}
+}
+
+func TestSwapValues(t *testing.T) {
+	// Our test tree
+	//
+	//    1
+	//   / \
+	//  2   3
+	//
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(2)
+	three := root.InsertRight(3)
+	two.AddAttribute("color", "red")
+	root.RecomputeBounds(binarytree.IntComparator)
+
+	two.SwapValues(three)
+	if two.Value != 3 || three.Value != 2 {
+		t.Fatalf("want values 3 and 2, got %d and %d", two.Value, three.Value)
+	}
+
+	if root.Left != two || root.Right != three || two.GetDotAttributes() != "color=red" {
+		t.Fatal("structure and attributes should be untouched")
+	}
+
+	if _, _, ok := root.Bounds(); ok {
+		t.Fatal("bounds should be invalidated")
+	}
+
+	root.SwapValues(root)
+	if root.Value != 1 {
+		t.Fatalf("swapping with itself should be a no-op, got %d", root.Value)
+	}
+}
+
+func TestBoundsInvalidation(t *testing.T) {
+	// Our test tree
+	//
+	//    5
+	//   / \
+	//  1   9
+	//
+	root := binarytree.Build(5).Left(1).Up().Right(9).Root()
+	nine := root.Right
+	root.RecomputeBounds(binarytree.IntComparator)
+
+	nine.Remove()
+	if _, _, ok := root.Bounds(); ok {
+		t.Fatal("bounds should be invalidated after Remove")
+	}
+
+	if !root.InRange(1, 8, binarytree.IntComparator) {
+		t.Fatal("tree should be in range [1, 8] after removing node (9)")
+	}
+
+	if lo, hi, ok := nine.Bounds(); !ok || lo != 9 || hi != 9 {
+		t.Fatalf("want bounds [9, 9] for the removed node, got [%d, %d]", lo, hi)
+	}
+
+	mutations := []struct {
+		desc   string
+		mutate func()
+	}{
+		{desc: "InsertRightNode", mutate: func() { root.InsertRightNode(binarytree.NewNode(12)) }},
+		{desc: "Prune", mutate: func() { root.Prune(func(n *binarytree.Node[int]) bool { return n.Value > 10 }) }},
+		{desc: "RemoveLastComplete", mutate: func() { root.RemoveLastComplete() }},
+	}
+
+	for _, test := range mutations {
+		root.RecomputeBounds(binarytree.IntComparator)
+		test.mutate()
+		if _, _, ok := root.Bounds(); ok {
+			t.Fatalf("%s: bounds should be invalidated", test.desc)
+		}
+	}
+}
+
+func TestRecomputeBounds(t *testing.T) {
+	// Our test tree
+	//
+	//      ____8
+	//     /     \
+	//    3__     10__
+	//   /   \        \
+	//  1     6        14
+	//       / \      /
+	//      4   7    13
+	//
+	root := binarytree.NewNode(8)
+	three := root.InsertLeft(3)
+	three.InsertLeft(1)
+	six := three.InsertRight(6)
+	six.InsertLeft(4)
+	six.InsertRight(7)
+	ten := root.InsertRight(10)
+	fourteen := ten.InsertRight(14)
+	fourteen.InsertLeft(13)
+
+	if _, _, ok := root.Bounds(); ok {
+		t.Fatal("bounds should not be cached yet")
+	}
+
+	// Brute-force scan of the sub-tree rooted at each node
+	checkBounds := func() {
+		root.WalkPreOrder(func(node *binarytree.Node[int]) error {
+			wantMin, wantMax := node.Value, node.Value
+			node.WalkPreOrder(func(n *binarytree.Node[int]) error {
+				if n.Value < wantMin {
+					wantMin = n.Value
+				}
+				if n.Value > wantMax {
+					wantMax = n.Value
+				}
+				return nil
+			})
+
+			gotMin, gotMax, ok := node.Bounds()
+			if !ok {
+				t.Fatalf("bounds of node (%d) should be cached", node.Value)
+			}
+			if gotMin != wantMin || gotMax != wantMax {
+				t.Fatalf("want bounds [%d, %d] for node (%d), got [%d, %d]", wantMin, wantMax, node.Value, gotMin, gotMax)
+			}
+			return nil
+		})
+	}
+
+	root.RecomputeBounds(binarytree.IntComparator)
+	checkBounds()
+
+	if !three.InRange(1, 7, binarytree.IntComparator) {
+		t.Fatal("sub-tree at node (3) should be within [1, 7]")
+	}
+
+	if ten.InRange(10, 13, binarytree.IntComparator) {
+		t.Fatal("sub-tree at node (10) should not be within [10, 13]")
+	}
+
+	// Repair the cache after a raw mutation
+	fourteen.InsertRight(20)
+	root.RecomputeBounds(binarytree.IntComparator)
+	checkBounds()
+}
+
+func TestDiameterPath(t *testing.T) {
+	values := func(nodes []*binarytree.Node[int]) []int {
+		result := make([]int, 0)
+		for _, node := range nodes {
+			result = append(result, node.Value)
+		}
+		return result
+	}
+
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(2)
+	root.InsertRight(3)
+	two.InsertLeft(4)
+	two.InsertRight(5)
+
+	path := root.DiameterPath()
+	want := []int{4, 2, 1, 3}
+	if got := values(path); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want diameter path %v, got %v", want, got)
+	}
+
+	if len(path)-1 != root.Diameter() {
+		t.Fatalf("want diameter path with %d edges, got %d", root.Diameter(), len(path)-1)
+	}
+
+	// A tree with a longest path, which does not pass through
+	// the root
+	//
+	//       1
+	//      /
+	//     2__
+	//    /   \
+	//   3     4
+	//  /       \
+	// 5         6
+	//
+	root = binarytree.NewNode(1)
+	two = root.InsertLeft(2)
+	two.InsertLeft(3).InsertLeft(5)
+	two.InsertRight(4).InsertRight(6)
+
+	path = root.DiameterPath()
+	want = []int{5, 3, 2, 4, 6}
+	if got := values(path); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want diameter path %v, got %v", want, got)
+	}
+
+	if len(path)-1 != root.Diameter() {
+		t.Fatalf("want diameter path with %d edges, got %d", root.Diameter(), len(path)-1)
+	}
+
+	// A single node is a path of zero edges
+	leaf := binarytree.NewNode(1)
+	want = []int{1}
+	if got := values(leaf.DiameterPath()); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want diameter path %v, got %v", want, got)
+	}
+}
+
+func TestClone(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(2)
+	root.InsertRight(3)
+	two.InsertLeft(4)
+	two.InsertRight(5)
+	two.AddAttribute("color", "green")
+	root.AddSkipNodeFunc(func(n *binarytree.Node[int]) bool {
+		return n.Value == 3
+	})
+
+	clone := root.Clone()
+	if clone == root || clone.Left == two {
+		t.Fatal("cloned nodes should be fresh allocations")
+	}
+
+	if clone.Left.Parent() != clone || clone.Left.Left.Parent() != clone.Left {
+		t.Fatal("cloned nodes should have their parent links set")
+	}
+
+	// The skip node handlers are cloned as well
+	result := make([]int, 0)
+	wantResult := []int{1, 2, 4, 5}
+	walkFunc := func(node *binarytree.Node[int]) error {
+		result = append(result, node.Value)
+		return nil
+	}
+
+	if err := clone.WalkPreOrder(walkFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(wantResult, result) {
+		t.Fatalf("want pre-order values %v, got %v", wantResult, result)
+	}
+
+	if clone.Left.GetDotAttributes() != "color=green" {
+		t.Fatal("attributes of node (2) should be cloned")
+	}
+
+	// Mutating the clone does not affect the original tree
+	clone.Left.Value = 20
+	clone.Left.AddAttribute("color", "red")
+	clone.AddSkipNodeFunc(func(n *binarytree.Node[int]) bool {
+		return n.Value == 4
+	})
+
+	if two.Value != 2 {
+		t.Fatal("value of original node (2) should be unchanged")
+	}
+
+	if two.GetDotAttributes() != "color=green" {
+		t.Fatal("attributes of original node (2) should be unchanged")
+	}
+
+	if root.SizeWalked() != 4 {
+		t.Fatal("skip node handlers of the original tree should be unchanged")
+	}
+}
+
+func TestStructureKey(t *testing.T) {
+	// Our test trees
+	//
+	//    1      1      1       1
+	//   / \    / \    /         \
+	//  2   3  2   3  2           2
+	//
+	first := binarytree.Build(1).Left(2).Up().Right(3).Root()
+	second := binarytree.Build(1).Left(2).Up().Right(3).Root()
+	third := binarytree.Build(1).Left(2).Root()
+	fourth := binarytree.Build(1).Right(2).Root()
+
+	valueKey := func(v int) string { return strconv.Itoa(v) }
+
+	counts := make(map[string]int)
+	for _, root := range []*binarytree.Node[int]{first, second, third, fourth} {
+		counts[root.StructureKey(valueKey)]++
+	}
+
+	if len(counts) != 3 {
+		t.Fatalf("want 3 distinct trees, got %d", len(counts))
+	}
+
+	if counts[first.StructureKey(valueKey)] != 2 {
+		t.Fatal("identical trees should produce the same key")
+	}
+
+	// Value keys containing separators do not produce ambiguous keys
+	a := binarytree.Build("x,#").Left("y").Root()
+	b := binarytree.Build("x").Right("#,y").Root()
+	identity := func(v string) string { return v }
+	if a.StructureKey(identity) == b.StructureKey(identity) {
+		t.Fatal("different trees should produce different keys")
+	}
+}
+
+func TestContainsSubtree(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \     \
+	// 4   5     6
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Right(6).Root()
+	eq := func(a, b int) bool { return a == b }
+
+	tests := []struct {
+		desc string
+		sub  *binarytree.Node[int]
+		want bool
+	}{
+		{desc: "whole tree", sub: root.Clone(), want: true},
+		{desc: "exact subtree", sub: binarytree.Build(2).Left(4).Up().Right(5).Root(), want: true},
+		{desc: "partial subtree", sub: binarytree.Build(2).Right(5).Root(), want: true},
+		{desc: "single inner node", sub: binarytree.NewNode(3), want: true},
+		{desc: "single leaf", sub: binarytree.NewNode(6), want: true},
+		{desc: "empty tree", sub: nil, want: true},
+		{desc: "missing value", sub: binarytree.NewNode(7), want: false},
+		{desc: "wrong side", sub: binarytree.Build(3).Left(6).Root(), want: false},
+		{desc: "too deep", sub: binarytree.Build(2).Left(4).Left(8).Root(), want: false},
+		{desc: "wrong value", sub: binarytree.Build(2).Left(5).Root(), want: false},
+	}
+
+	for _, test := range tests {
+		if got := root.ContainsSubtree(test.sub, eq); got != test.want {
+			t.Fatalf("%s: want %t, got %t", test.desc, test.want, got)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	a := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	b := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	if !binarytree.Equal(a, b) {
+		t.Fatal("trees should be equal")
+	}
+
+	cmp := func(x, y int) bool { return x%10 == y%10 }
+	b.Right.Value = 13
+	if binarytree.Equal(a, b) {
+		t.Fatal("trees should not be equal")
+	}
+
+	if !a.Equal(b, cmp) {
+		t.Fatal("trees should be equal with custom comparator")
+	}
+
+	// Different shapes
+	b.Right.InsertLeft(6)
+	if a.Equal(b, cmp) {
+		t.Fatal("trees with different shapes should not be equal")
+	}
+
+	// Nil trees
+	var empty *binarytree.Node[int]
+	if !binarytree.Equal(empty, nil) {
+		t.Fatal("nil trees should be equal")
+	}
+
+	if binarytree.Equal(a, nil) || empty.Equal(a, cmp) {
+		t.Fatal("nil tree should not equal non-nil tree")
+	}
+
+	// Short-circuits as soon as a mismatch is found
+	calls := 0
+	counting := func(x, y int) bool {
+		calls++
+		return false
+	}
+	if a.Equal(b, counting) || calls != 1 {
+		t.Fatalf("want a single comparison, got %d", calls)
+	}
+}
+
+func TestWalkLevelOrderWithMarker(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	rows := make([][]int, 0)
+	row := make([]int, 0)
+	levels := make([]int, 0)
+	nodeFunc := func(n *binarytree.Node[int]) error {
+		row = append(row, n.Value)
+		return nil
+	}
+	endOfLevel := func(level int) error {
+		levels = append(levels, level)
+		rows = append(rows, row)
+		row = make([]int, 0)
+		return nil
+	}
+
+	if err := root.WalkLevelOrderWithMarker(nodeFunc, endOfLevel); err != nil {
+		t.Fatal(err)
+	}
+
+	wantLevels := []int{0, 1, 2}
+	if !reflect.DeepEqual(levels, wantLevels) {
+		t.Fatalf("want level markers %v, got %v", wantLevels, levels)
+	}
+
+	wantRows := [][]int{{1}, {2, 3}, {4, 5}}
+	if !reflect.DeepEqual(rows, wantRows) {
+		t.Fatalf("want rows %v, got %v", wantRows, rows)
+	}
+
+	// Errors from the marker stop the walk
+	errStop := errors.New("stop")
+	stopAtFirst := func(level int) error {
+		return errStop
+	}
+	visited := 0
+	countNodes := func(n *binarytree.Node[int]) error {
+		visited++
+		return nil
+	}
+	if err := root.WalkLevelOrderWithMarker(countNodes, stopAtFirst); err != errStop {
+		t.Fatalf("want stop error, got %v", err)
+	}
+	if visited != 1 {
+		t.Fatalf("want 1 visited node, got %d", visited)
+	}
+}
+
+func TestInvert(t *testing.T) {
+	// Our test tree and its mirror image
+	//
+	//     __1          1__
+	//    /   \        /   \
+	//   2     3      3     2
+	//  / \                / \
+	// 4   5              5   4
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	want := binarytree.Build(1).Left(3).Up().Right(2).Left(5).Up().Right(4).Root()
+
+	mirrored := root.Mirrored()
+	if !binarytree.Equal(mirrored, want) {
+		t.Fatal("mirrored tree mismatch")
+	}
+
+	// The original tree is not modified by Mirrored
+	if root.Left.Value != 2 {
+		t.Fatal("original tree should not be modified")
+	}
+
+	root.Invert()
+	result := make([]int, 0)
+	wantResult := []int{1, 3, 2, 5, 4}
+	walkFunc := func(node *binarytree.Node[int]) error {
+		result = append(result, node.Value)
+		return nil
+	}
+
+	if err := root.WalkLevelOrder(walkFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(wantResult, result) {
+		t.Fatalf("want level-order values %v, got %v", wantResult, result)
+	}
+
+	// Parent links are preserved
+	two := root.Right
+	if two.Parent() != root || two.Left.Parent() != two || two.Right.Parent() != two {
+		t.Fatal("parent links should be preserved after inverting")
+	}
+}
+
+func TestIsSymmetric(t *testing.T) {
+	cmp := func(a, b int) bool { return a == b }
+
+	// A symmetric tree
+	//
+	//     __1__
+	//    /     \
+	//   2       2
+	//  / \     / \
+	// 3   4   4   3
+	//
+	root := binarytree.Build(1).Left(2).Left(3).Up().Right(4).Up().Up().Right(2).Left(4).Up().Right(3).Root()
+	if !root.IsSymmetric(cmp) {
+		t.Fatal("tree should be symmetric")
+	}
+
+	// Not a symmetric tree
+	//
+	//    1
+	//   / \
+	//  2   2
+	//   \   \
+	//    3   3
+	//
+	root = binarytree.Build(1).Left(2).Right(3).Up().Up().Right(2).Right(3).Root()
+	if root.IsSymmetric(cmp) {
+		t.Fatal("tree should not be symmetric")
+	}
+
+	// Not a symmetric tree
+	//
+	//    1
+	//   / \
+	//  2   3
+	//
+	root = binarytree.Build(1).Left(2).Up().Right(3).Root()
+	if root.IsSymmetric(cmp) {
+		t.Fatal("tree should not be symmetric")
+	}
+
+	// A single root node is symmetric
+	root = binarytree.NewNode(1)
+	if !root.IsSymmetric(cmp) {
+		t.Fatal("single root node should be symmetric")
+	}
+}
+
+func TestPrettyPrint(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \     \
+	// 4   5     6
+	//    /
+	//   7
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Left(7).Up().Up().Up().Right(3).Right(6).Root()
+
+	var buf bytes.Buffer
+	if err := root.PrettyPrint(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `1
+├── 2
+│   ├── 4
+│   └── 5
+│       ├── 7
+│       └── <nil>
+└── 3
+    ├── <nil>
+    └── 6
+`
+	if buf.String() != want {
+		t.Fatalf("want output:\n%s\ngot:\n%s", want, buf.String())
+	}
+
+	// Single node with a multi-line label
+	buf.Reset()
+	node := binarytree.NewNode("a\nb")
+	if err := node.PrettyPrint(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := buf.String(); got != "a\\nb\n" {
+		t.Fatalf("want escaped label, got %q", got)
+	}
+
+	// Skipped nodes are not printed
+	buf.Reset()
+	root.AddSkipNodeFunc(func(node *binarytree.Node[int]) bool {
+		return node.Value == 2
+	})
+	if err := root.PrettyPrint(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	want = `1
+├── <nil>
+└── 3
+    ├── <nil>
+    └── 6
+`
+	if buf.String() != want {
+		t.Fatalf("want output:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
+
+func TestWriteMermaid(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	var buf bytes.Buffer
+	if err := root.WriteMermaid(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	if !strings.HasPrefix(output, "graph TD\n") {
+		t.Fatal("missing graph TD header")
+	}
+
+	nodes := regexp.MustCompile(`(?m)^\tn\d+\["(\d)"\]$`).FindAllStringSubmatch(output, -1)
+	labels := make([]string, 0)
+	for _, node := range nodes {
+		labels = append(labels, node[1])
+	}
+
+	want := []string{"1", "2", "4", "5", "3"}
+	if !reflect.DeepEqual(labels, want) {
+		t.Fatalf("want node labels %v, got %v", want, labels)
+	}
+
+	edges := regexp.MustCompile(`(?m)^\tn\d+ --> n\d+$`).FindAllString(output, -1)
+	if len(edges) != 4 {
+		t.Fatalf("want 4 edges, got %d", len(edges))
+	}
+
+	// Labels are sanitized
+	buf.Reset()
+	node := binarytree.NewNode("say \"hi\"\nbye")
+	if err := node.WriteMermaid(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), `["say #quot;hi#quot;<br>bye"]`) {
+		t.Fatalf("unexpected sanitized label in %q", buf.String())
+	}
+}
+
+func TestWritePlantUML(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	var buf bytes.Buffer
+	if err := root.WritePlantUML(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	if !strings.HasPrefix(output, "@startuml\n") {
+		t.Fatal("missing @startuml header")
+	}
+
+	if !strings.HasSuffix(output, "@enduml\n") {
+		t.Fatal("missing @enduml footer")
+	}
+
+	if got := strings.Count(output, "-->"); got != 4 {
+		t.Fatalf("want 4 edges, got %d", got)
+	}
+
+	if !strings.Contains(output, "object \"1\" as n1\n") {
+		t.Fatal("missing root node object")
+	}
+
+	// Labels are sanitized
+	var labelBuf bytes.Buffer
+	node := binarytree.NewNode("say \"hi\"\nbye")
+	if err := node.WritePlantUML(&labelBuf); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "object \"say 'hi'\\nbye\" as n1\n"
+	if !strings.Contains(labelBuf.String(), want) {
+		t.Fatalf("want sanitized label %q, got %q", want, labelBuf.String())
+	}
+}
+
+func TestDiameter(t *testing.T) {
+	// A single node
+	root := binarytree.NewNode(1)
+	if got := root.Diameter(); got != 0 {
+		t.Fatalf("want diameter 0, got %d", got)
+	}
+
+	// A tree with two nodes
+	root.InsertLeft(2)
+	if got := root.Diameter(); got != 1 {
+		t.Fatalf("want diameter 1, got %d", got)
+	}
+
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root = binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	if got := root.Diameter(); got != 3 {
+		t.Fatalf("want diameter 3, got %d", got)
+	}
+
+	// A tree with a longest path, which does not pass through
+	// the root
+	//
+	//       1
+	//      /
+	//     2__
+	//    /   \
+	//   3     4
+	//  /       \
+	// 5         6
+	//
+	root = binarytree.Build(1).Left(2).Left(3).Left(5).Up().Up().Right(4).Right(6).Root()
+	if got := root.Diameter(); got != 4 {
+		t.Fatalf("want diameter 4, got %d", got)
+	}
+}
+
+func TestSearchBST(t *testing.T) {
+	// Our test tree
+	//
+	//      ____8
+	//     /     \
+	//    3__     10__
+	//   /   \        \
+	//  1     6        14
+	//       / \      /
+	//      4   7    13
+	//
+	root := binarytree.NewNode(8)
+	three := root.InsertLeft(3)
+	three.InsertLeft(1)
+	six := three.InsertRight(6)
+	six.InsertLeft(4)
+	six.InsertRight(7)
+	ten := root.InsertRight(10)
+	fourteen := ten.InsertRight(14)
+	fourteen.InsertLeft(13)
+
+	for value := 0; value <= 15; value++ {
+		got, gotOk := root.SearchBST(value, binarytree.IntComparator)
+		want, wantOk := root.FindNode(func(node *binarytree.Node[int]) bool {
+			return node.Value == value
+		})
+
+		if got != want || gotOk != wantOk {
+			t.Fatalf("SearchBST and FindNode disagree for value %d", value)
+		}
+	}
+}
+
+func TestDeleteBST(t *testing.T) {
+	// Our test tree
+	//
+	//      ____8
+	//     /     \
+	//    3__     10__
+	//   /   \        \
+	//  1     6        14
+	//       / \      /
+	//      4   7    13
+	//
+	newTree := func() *binarytree.Node[int] {
+		root := binarytree.NewNode(8)
+		for _, v := range []int{3, 10, 1, 6, 14, 4, 7, 13} {
+			root.InsertBST(v, binarytree.IntComparator)
+		}
+		return root
+	}
+
+	eq := func(a, b int) bool { return a == b }
+	tests := []struct {
+		desc  string
+		value int
+		want  []int
+	}{
+		{desc: "leaf", value: 4, want: []int{8, 3, 1, 6, 7, 10, 14, 13}},
+		{desc: "single child", value: 14, want: []int{8, 3, 1, 6, 4, 7, 10, 13}},
+		{desc: "two children", value: 3, want: []int{8, 4, 1, 6, 7, 10, 14, 13}},
+		{desc: "root", value: 8, want: []int{10, 3, 1, 6, 4, 7, 14, 13}},
+	}
+
+	for _, test := range tests {
+		root := newTree()
+		root, ok := root.DeleteBST(test.value, binarytree.IntComparator)
+		if !ok {
+			t.Fatalf("%s: value %d should be deleted", test.desc, test.value)
+		}
+
+		if !root.IsBinarySearchTree(binarytree.IntComparator) {
+			t.Fatalf("%s: tree should be BST after deletion", test.desc)
+		}
+
+		if !root.SequenceEqual(binarytree.PreOrder, test.want, eq) {
+			t.Fatalf("%s: unexpected tree after deletion", test.desc)
+		}
+	}
+
+	// Deleting a missing value
+	root := newTree()
+	got, ok := root.DeleteBST(5, binarytree.IntComparator)
+	if ok || got != root || root.Size() != 9 {
+		t.Fatal("deleting a missing value should not modify the tree")
+	}
+
+	// Deleting a root with a single child promotes the child
+	root = binarytree.NewNode(1)
+	root.InsertBST(2, binarytree.IntComparator)
+	got, ok = root.DeleteBST(1, binarytree.IntComparator)
+	if !ok || got.Value != 2 || got.Parent() != nil {
+		t.Fatal("child of the deleted root should be the new root")
+	}
+
+	// Deleting the only node yields an empty tree
+	got, ok = got.DeleteBST(2, binarytree.IntComparator)
+	if !ok || got != nil {
+		t.Fatal("deleting the only node should yield an empty tree")
+	}
+
+	// Cached bounds are maintained
+	root = newTree()
+	root.RecomputeBounds(binarytree.IntComparator)
+	root, _ = root.DeleteBST(1, binarytree.IntComparator)
+	root, _ = root.DeleteBST(14, binarytree.IntComparator)
+	if lo, hi, ok := root.Bounds(); !ok || lo != 3 || hi != 13 {
+		t.Fatalf("want bounds [3, 13], got [%d, %d]", lo, hi)
+	}
+
+	// Deleting the root of a sub-tree relinks its parent
+	//
+	//      8
+	//     / \
+	//    3   10
+	//   /
+	//  1
+	//
+	root = binarytree.NewNode(8)
+	for _, v := range []int{3, 10, 1} {
+		root.InsertBST(v, binarytree.IntComparator)
+	}
+	root.RecomputeSizes()
+	root.RecomputeBounds(binarytree.IntComparator)
+	three := root.Left
+	got, ok = three.DeleteBST(3, binarytree.IntComparator)
+	if !ok || got != root.Left || got.Value != 1 || got.Parent() != root {
+		t.Fatal("node (1) should replace node (3) as the left child of the root")
+	}
+
+	if three.Parent() != nil || !root.SequenceEqual(binarytree.InOrder, []int{1, 8, 10}, eq) {
+		t.Fatal("node (3) should be detached from the tree")
+	}
+
+	if root.Size() != 3 {
+		t.Fatalf("want cached size 3, got %d", root.Size())
+	}
+
+	if lo, hi, ok := root.Bounds(); !ok || lo != 1 || hi != 10 {
+		t.Fatalf("want bounds [1, 10], got [%d, %d]", lo, hi)
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	// Our test tree
+	//
+	//      ____8
+	//     /     \
+	//    3__     10__
+	//   /   \        \
+	//  1     6        14
+	//       / \      /
+	//      4   7    13
+	//
+	root := binarytree.NewNode(8)
+	for _, v := range []int{3, 10, 1, 6, 14, 4, 7, 13} {
+		root.InsertBST(v, binarytree.IntComparator)
+	}
+
+	if got := root.Min().Value; got != 1 {
+		t.Fatalf("want min value 1, got %d", got)
+	}
+
+	if got := root.Max().Value; got != 14 {
+		t.Fatalf("want max value 14, got %d", got)
+	}
+
+	// Min and Max of sub-trees
+	if got := root.Left.Right.Min().Value; got != 4 {
+		t.Fatalf("want min value 4, got %d", got)
+	}
+
+	if got := root.Right.Max().Value; got != 14 {
+		t.Fatalf("want max value 14, got %d", got)
+	}
+
+	// A single node is both the min and the max
+	leaf := root.Right.Right.Left
+	if leaf.Min() != leaf || leaf.Max() != leaf {
+		t.Fatal("leaf node should be its own min and max")
+	}
+}
+
+func TestSearchPathBST(t *testing.T) {
+	// Our test tree
+	//
+	//      ____8
+	//     /     \
+	//    3__     10__
+	//   /   \        \
+	//  1     6        14
+	//       / \      /
+	//      4   7    13
+	//
+	root := binarytree.NewNode(8)
+	three := root.InsertLeft(3)
+	three.InsertLeft(1)
+	six := three.InsertRight(6)
+	six.InsertLeft(4)
+	six.InsertRight(7)
+	ten := root.InsertRight(10)
+	fourteen := ten.InsertRight(14)
+	fourteen.InsertLeft(13)
+
+	values := func(nodes []*binarytree.Node[int]) []int {
+		result := make([]int, 0)
+		for _, node := range nodes {
+			result = append(result, node.Value)
+		}
+		return result
+	}
+
+	path, ok := root.SearchPathBST(7, binarytree.IntComparator)
+	if !ok {
+		t.Fatal("value 7 should be found")
+	}
+
+	want := []int{8, 3, 6, 7}
+	if got := values(path); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want search path %v, got %v", want, got)
+	}
+
+	path, ok = root.SearchPathBST(12, binarytree.IntComparator)
+	if ok {
+		t.Fatal("value 12 should not be found")
+	}
+
+	want = []int{8, 10, 14, 13}
+	if got := values(path); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want search path %v, got %v", want, got)
+	}
+}
+
+func TestMinCameras(t *testing.T) {
+	// A single camera at node (2) covers the whole tree
+	//
+	//       1
+	//      /
+	//     2
+	//    / \
+	//   3   4
+	//
+	root := binarytree.Build(1).Left(2).Left(3).Up().Right(4).Root()
+	if got := root.MinCameras(); got != 1 {
+		t.Fatalf("want 1 camera, got %d", got)
+	}
+
+	// Cameras at nodes (2) and (4)
+	//
+	//         1
+	//        /
+	//       2
+	//      /
+	//     3
+	//    /
+	//   4
+	//    \
+	//     5
+	//
+	root = binarytree.Build(1).Left(2).Left(3).Left(4).Right(5).Root()
+	if got := root.MinCameras(); got != 2 {
+		t.Fatalf("want 2 cameras, got %d", got)
+	}
+
+	// A single node needs a camera
+	root = binarytree.NewNode(1)
+	if got := root.MinCameras(); got != 1 {
+		t.Fatalf("want 1 camera, got %d", got)
+	}
+}
+
+func TestDepth(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(2)
+	three := root.InsertRight(3)
+	two.InsertLeft(4)
+	five := two.InsertRight(5)
+
+	tests := []struct {
+		from   *binarytree.Node[int]
+		target *binarytree.Node[int]
+		depth  int
+		found  bool
+	}{
+		{from: root, target: root, depth: 0, found: true},
+		{from: root, target: three, depth: 1, found: true},
+		{from: root, target: five, depth: 2, found: true},
+		{from: two, target: five, depth: 1, found: true},
+		{from: two, target: three, depth: 0, found: false},
+		{from: root, target: binarytree.NewNode(5), depth: 0, found: false},
+	}
+
+	for _, test := range tests {
+		depth, found := test.from.Depth(test.target)
+		if depth != test.depth || found != test.found {
+			t.Fatalf("want depth %d (found %t) of node (%d) from node (%d), got %d (found %t)",
+				test.depth, test.found, test.target.Value, test.from.Value, depth, found)
+		}
+	}
+}
+
+func TestMeta(t *testing.T) {
+	type scratch struct {
+		Visited bool
+		Score   int
+	}
+
+	root := binarytree.NewNode(1)
+	if root.Meta() != nil {
+		t.Fatal("node should have no metadata")
+	}
+
+	root.SetMeta(scratch{Visited: true, Score: 42})
+	meta, ok := root.Meta().(scratch)
+	if !ok {
+		t.Fatal("metadata should be of type scratch")
+	}
+
+	if !meta.Visited || meta.Score != 42 {
+		t.Fatalf("unexpected metadata %+v", meta)
+	}
+
+	// Metadata is not part of the Dot attributes
+	if root.GetDotAttributes() != "" {
+		t.Fatal("node is expected to have no attributes")
+	}
+}
+
+func TestWalkLevelOrderBottomUp(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	result := make([]int, 0)
+	wantResult := []int{4, 5, 2, 3, 1}
+	walkFunc := func(node *binarytree.Node[int]) error {
+		result = append(result, node.Value)
+		return nil
+	}
+
+	if err := root.WalkLevelOrderBottomUp(walkFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(wantResult, result) {
+		t.Fatalf("want bottom-up level-order values %v, got %v", wantResult, result)
+	}
+
+	// Skip the sub-tree at node (2)
+	root.AddSkipNodeFunc(func(n *binarytree.Node[int]) bool {
+		return n.Value == 2
+	})
+
+	result = make([]int, 0)
+	wantResult = []int{3, 1}
+	if err := root.WalkLevelOrderBottomUp(walkFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(wantResult, result) {
+		t.Fatalf("want bottom-up level-order values %v, got %v", wantResult, result)
+	}
+
+	// Errors stop the walk
+	errStop := errors.New("stop")
+	visited := 0
+	stopFunc := func(node *binarytree.Node[int]) error {
+		visited++
+		return errStop
+	}
+
+	if err := root.WalkLevelOrderBottomUp(stopFunc); err != errStop {
+		t.Fatalf("want stop error, got %v", err)
+	}
+
+	if visited != 1 {
+		t.Fatalf("want 1 visited node, got %d", visited)
+	}
+}
+
+func TestSubtrees(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	internal := func(n *binarytree.Node[int]) bool {
+		return !n.IsLeafNode()
+	}
+
+	subtrees := root.Subtrees(internal)
+	if len(subtrees) != 2 {
+		t.Fatalf("want 2 sub-trees, got %d", len(subtrees))
+	}
+
+	if subtrees[0] != root || subtrees[1] != root.Left {
+		t.Fatal("sub-tree roots should be nodes (1) and (2)")
+	}
+
+	wantSizes := []int{5, 3}
+	for i, subtree := range subtrees {
+		if subtree.Size() != wantSizes[i] {
+			t.Fatalf("want sub-tree size %d, got %d", wantSizes[i], subtree.Size())
+		}
+	}
+
+	none := func(n *binarytree.Node[int]) bool {
+		return false
+	}
+	if got := root.Subtrees(none); got == nil || len(got) != 0 {
+		t.Fatal("want empty slice of sub-trees")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	root.RecomputeSizes()
+	two := root.Left
+
+	even := func(n *binarytree.Node[int]) bool {
+		return n.Value%2 == 0
+	}
+
+	if got := root.Prune(even); got != 3 {
+		t.Fatalf("want 3 removed nodes, got %d", got)
+	}
+
+	if root.Left != nil || two.Parent() != nil {
+		t.Fatal("node (2) should be detached from the tree")
+	}
+
+	if got := root.Size(); got != 2 {
+		t.Fatalf("want cached size 2, got %d", got)
+	}
+
+	// The root is left intact
+	all := func(n *binarytree.Node[int]) bool {
+		return true
+	}
+	if got := root.Prune(all); got != 1 {
+		t.Fatalf("want 1 removed node, got %d", got)
+	}
+
+	if !root.IsLeafNode() || root.Value != 1 {
+		t.Fatal("root node (1) should be left intact")
+	}
+
+	// Children linked via the Left/Right fields have no parent link
+	root = &binarytree.Node[int]{Value: 1}
+	root.Left = &binarytree.Node[int]{Value: 2}
+	root.Left.Left = &binarytree.Node[int]{Value: 4}
+	root.Right = &binarytree.Node[int]{Value: 3}
+	if got := root.Prune(even); got != 2 {
+		t.Fatalf("want 2 removed nodes, got %d", got)
+	}
+
+	if root.Left != nil || root.Right == nil || root.Size() != 2 {
+		t.Fatal("node (2) should be pruned from the tree")
+	}
+}
+
+func TestWalkLevelOrderBounded(t *testing.T) {
+	// Our test tree
+	//
+	//     __1__
+	//    /     \
+	//   2       3
+	//  / \     / \
+	// 4   5   6   7
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Left(6).Up().Right(7).Root()
+
+	result := make([]int, 0)
+	walkFunc := func(node *binarytree.Node[int]) error {
+		result = append(result, node.Value)
+		return nil
+	}
+
+	// The widest level has 4 nodes
+	if err := root.WalkLevelOrderBounded(4, walkFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	wantResult := []int{1, 2, 3, 4, 5, 6, 7}
+	if !reflect.DeepEqual(result, wantResult) {
+		t.Fatalf("want level-order values %v, got %v", wantResult, result)
+	}
+
+	result = make([]int, 0)
+	err := root.WalkLevelOrderBounded(2, walkFunc)
+	if !errors.Is(err, binarytree.ErrQueueLimitExceeded) {
+		t.Fatalf("want queue limit exceeded error, got %v", err)
+	}
+
+	wantResult = []int{1, 2}
+	if !reflect.DeepEqual(result, wantResult) {
+		t.Fatalf("want level-order values %v, got %v", wantResult, result)
+	}
+
+	// Non-positive limits are rejected without visiting any node
+	for _, limit := range []int{0, -1} {
+		result = make([]int, 0)
+		err := root.WalkLevelOrderBounded(limit, walkFunc)
+		if !errors.Is(err, binarytree.ErrInvalidQueueLimit) {
+			t.Fatalf("want invalid queue limit error for %d, got %v", limit, err)
+		}
+
+		if len(result) != 0 {
+			t.Fatalf("want no visited nodes, got %v", result)
+		}
+	}
+
+	// A single node fits in a queue of one
+	leaf := binarytree.NewNode(1)
+	if err := leaf.WalkLevelOrderBounded(1, walkFunc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWalkWithDepth(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	type visit struct {
+		value int
+		depth int
+	}
+
+	result := make([]visit, 0)
+	walkFunc := func(node *binarytree.Node[int], depth int) error {
+		result = append(result, visit{value: node.Value, depth: depth})
+		return nil
+	}
+
+	if err := root.WalkPreOrderWithDepth(walkFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []visit{{1, 0}, {2, 1}, {4, 2}, {5, 2}, {3, 1}}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("want pre-order visits %v, got %v", want, result)
+	}
+
+	result = make([]visit, 0)
+	if err := root.WalkLevelOrderWithDepth(walkFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	want = []visit{{1, 0}, {2, 1}, {3, 1}, {4, 2}, {5, 2}}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("want level-order visits %v, got %v", want, result)
+	}
+
+	// Depth is relative to the node the walk starts from
+	result = make([]visit, 0)
+	if err := root.Left.WalkPreOrderWithDepth(walkFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	want = []visit{{2, 0}, {4, 1}, {5, 1}}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("want pre-order visits %v, got %v", want, result)
+	}
+}
+
+func TestWalkContext(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	type walker func(ctx context.Context, walkFunc binarytree.WalkFunc[int]) error
+	walkers := map[string]walker{
+		"in-order":    root.WalkInOrderContext,
+		"pre-order":   root.WalkPreOrderContext,
+		"post-order":  root.WalkPostOrderContext,
+		"level-order": root.WalkLevelOrderContext,
+	}
+
+	for name, walk := range walkers {
+		// Walking with a live context visits all nodes
+		visited := 0
+		countFunc := func(node *binarytree.Node[int]) error {
+			visited++
+			return nil
+		}
+
+		if err := walk(context.Background(), countFunc); err != nil {
+			t.Fatal(err)
+		}
+
+		if visited != 5 {
+			t.Fatalf("want 5 visited nodes in %s walk, got %d", name, visited)
+		}
+
+		// Cancel the context after visiting two nodes
+		ctx, cancel := context.WithCancel(context.Background())
+		visited = 0
+		cancelFunc := func(node *binarytree.Node[int]) error {
+			visited++
+			if visited == 2 {
+				cancel()
+			}
+			return nil
+		}
+
+		err := walk(ctx, cancelFunc)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("want context canceled error in %s walk, got %v", name, err)
+		}
+
+		if visited != 2 {
+			t.Fatalf("want 2 visited nodes in %s walk, got %d", name, visited)
+		}
+	}
+}
+
+func TestWalkInOrderProgress(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	walkFunc := func(node *binarytree.Node[int]) error {
+		return nil
+	}
+
+	tests := []struct {
+		every int
+		want  []int
+	}{
+		{every: 1, want: []int{1, 2, 3, 4, 5}},
+		{every: 2, want: []int{2, 4}},
+		{every: 5, want: []int{5}},
+		{every: 10, want: []int{}},
+		{every: 0, want: []int{}},
+	}
+
+	for _, test := range tests {
+		reports := make([]int, 0)
+		progress := func(count int) {
+			reports = append(reports, count)
+		}
+
+		if err := root.WalkInOrderProgress(walkFunc, test.every, progress); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(reports, test.want) {
+			t.Fatalf("want progress reports %v for every %d, got %v", test.want, test.every, reports)
+		}
+	}
+
+	// Nodes for which walkFunc fails are not counted
+	reports := make([]int, 0)
+	progress := func(count int) {
+		reports = append(reports, count)
+	}
+	failFunc := func(node *binarytree.Node[int]) error {
+		if node.Value == 1 {
+			return errors.New("failed")
+		}
+		return nil
+	}
+
+	if err := root.WalkInOrderProgress(failFunc, 1, progress); err == nil {
+		t.Fatal("expected walk to fail")
+	}
+
+	if !reflect.DeepEqual(reports, []int{1, 2, 3}) {
+		t.Fatalf("want progress reports [1 2 3], got %v", reports)
+	}
+}
+
+func TestWalkProgress(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	type walker func(walkFunc binarytree.WalkFunc[int], every int, progress func(count int)) error
+	tests := []struct {
+		name string
+		walk walker
+		want []int
+	}{
+		{name: "in-order", walk: root.WalkInOrderProgress, want: []int{4, 2, 5, 1, 3}},
+		{name: "pre-order", walk: root.WalkPreOrderProgress, want: []int{1, 2, 4, 5, 3}},
+		{name: "post-order", walk: root.WalkPostOrderProgress, want: []int{4, 5, 2, 3, 1}},
+		{name: "level-order", walk: root.WalkLevelOrderProgress, want: []int{1, 2, 3, 4, 5}},
+	}
+
+	for _, test := range tests {
+		values := make([]int, 0)
+		walkFunc := func(node *binarytree.Node[int]) error {
+			values = append(values, node.Value)
+			return nil
+		}
+
+		// Record the last visited value on each report
+		reports := make([]int, 0)
+		progress := func(count int) {
+			reports = append(reports, values[count-1])
+		}
+
+		if err := test.walk(walkFunc, 2, progress); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(values, test.want) {
+			t.Fatalf("want %s values %v, got %v", test.name, test.want, values)
+		}
+
+		wantReports := []int{test.want[1], test.want[3]}
+		if !reflect.DeepEqual(reports, wantReports) {
+			t.Fatalf("want %s progress reports after %v, got %v", test.name, wantReports, reports)
+		}
+	}
+}
+
+func TestErrStopWalk(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	walkers := map[string]func(walkFunc binarytree.WalkFunc[int]) error{
+		"in-order":    root.WalkInOrder,
+		"pre-order":   root.WalkPreOrder,
+		"post-order":  root.WalkPostOrder,
+		"level-order": root.WalkLevelOrder,
+	}
+
+	for name, walk := range walkers {
+		visited := 0
+		walkFunc := func(node *binarytree.Node[int]) error {
+			visited++
+			if visited == 2 {
+				return binarytree.ErrStopWalk
+			}
+			return nil
+		}
+
+		if err := walk(walkFunc); err != nil {
+			t.Fatalf("want no error from %s walk, got %v", name, err)
+		}
+
+		if visited != 2 {
+			t.Fatalf("want 2 visited nodes in %s walk, got %d", name, visited)
+		}
+	}
+}
+
+func TestAppendComplete(t *testing.T) {
+	root := binarytree.NewNode(1)
+	for i := 2; i <= 10; i++ {
+		node := root.AppendComplete(i)
+		if node.Value != i {
+			t.Fatalf("want appended node (%d), got (%d)", i, node.Value)
+		}
+
+		if !root.IsCompleteTree() {
+			t.Fatalf("tree should be complete after appending (%d)", i)
+		}
+	}
+
+	eq := func(a, b int) bool { return a == b }
+	want := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if !root.SequenceEqual(binarytree.LevelOrder, want, eq) {
+		t.Fatal("appended nodes should be in level-order")
+	}
+
+	// The new node is attached to the first node missing a child
+	//
+	//     __1__
+	//    /     \
+	//   2       3
+	//  / \
+	// 4   5
+	//
+	root = binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	six := root.AppendComplete(6)
+	if six.Parent() != root.Right || root.Right.Left != six {
+		t.Fatal("node (6) should be the left child of node (3)")
+	}
+}
+
+func TestMap(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	mapped := binarytree.Map(root, func(v int) string {
+		return strconv.Itoa(v * 10)
+	})
+
+	want := binarytree.Build("10").Left("20").Left("40").Up().Right("50").Up().Up().Right("30").Root()
+	if !binarytree.Equal(mapped, want) {
+		t.Fatal("mapped tree mismatch")
+	}
+
+	if mapped.Left.Parent() != mapped {
+		t.Fatal("mapped nodes should have their parent links set")
+	}
+
+	if binarytree.Map[int, int](nil, func(v int) int { return v }) != nil {
+		t.Fatal("mapping a nil tree should return nil")
+	}
+}
+
+func TestRemoveLastComplete(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	original := root.Clone()
+
+	root.AppendComplete(6)
+	root.AppendComplete(7)
+
+	for _, want := range []int{7, 6} {
+		node, ok := root.RemoveLastComplete()
+		if !ok {
+			t.Fatal("last node should be removed")
+		}
+
+		if node.Value != want || node.Parent() != nil {
+			t.Fatalf("want detached node (%d), got (%d)", want, node.Value)
+		}
+
+		if !root.IsCompleteTree() {
+			t.Fatal("tree should remain complete")
+		}
+	}
+
+	if !binarytree.Equal(root, original) {
+		t.Fatal("tree should be restored to its original structure")
+	}
+
+	// The root node cannot be removed
+	leaf := binarytree.NewNode(1)
+	if node, ok := leaf.RemoveLastComplete(); ok || node != nil {
+		t.Fatal("root node should not be removed")
+	}
+}
+
+func TestFold(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	sum := binarytree.Fold(root, 0, func(acc, v int) int { return acc + v }, binarytree.InOrder)
+	if sum != 15 {
+		t.Fatalf("want sum 15, got %d", sum)
+	}
+
+	concat := func(acc string, v int) string { return acc + strconv.Itoa(v) }
+	tests := []struct {
+		order binarytree.TraversalOrder
+		want  string
+	}{
+		{order: binarytree.InOrder, want: "42513"},
+		{order: binarytree.PreOrder, want: "12453"},
+		{order: binarytree.PostOrder, want: "45231"},
+		{order: binarytree.LevelOrder, want: "12345"},
+	}
+
+	for _, test := range tests {
+		if got := binarytree.Fold(root, "", concat, test.order); got != test.want {
+			t.Fatalf("want folded value %q in order %d, got %q", test.want, test.order, got)
+		}
+	}
+
+	if got := binarytree.Fold(nil, "init", concat, binarytree.InOrder); got != "init" {
+		t.Fatalf("want initial value for nil tree, got %q", got)
+	}
+}
+
+func TestStats(t *testing.T) {
+	// Our test tree
+	//
+	//      ____8
+	//     /     \
+	//    3__     10__
+	//   /   \        \
+	//  1     6        14
+	//       / \      /
+	//      4   7    13
+	//
+	root := binarytree.NewNode(8)
+	for _, v := range []int{3, 10, 1, 6, 14, 4, 7, 13} {
+		root.InsertBST(v, binarytree.IntComparator)
+	}
+
+	less := func(a, b int) bool { return a < b }
+	min, max, count := binarytree.Stats(root, less)
+	if min != 1 || max != 14 || count != 9 {
+		t.Fatalf("want (1, 14, 9), got (%d, %d, %d)", min, max, count)
+	}
+
+	// Reversed ordering swaps min and max
+	greater := func(a, b int) bool { return a > b }
+	min, max, _ = binarytree.Stats(root, greater)
+	if min != 14 || max != 1 {
+		t.Fatalf("want (14, 1), got (%d, %d)", min, max)
+	}
+
+	// Empty tree
+	min, max, count = binarytree.Stats(nil, less)
+	if min != 0 || max != 0 || count != 0 {
+		t.Fatalf("want zero values for empty tree, got (%d, %d, %d)", min, max, count)
+	}
+
+	// Strings by length
+	words := binarytree.Build("tree").Left("a").Up().Right("binary").Root()
+	byLen := func(a, b string) bool { return len(a) < len(b) }
+	shortest, longest, count := binarytree.Stats(words, byLen)
+	if shortest != "a" || longest != "binary" || count != 3 {
+		t.Fatalf("want (a, binary, 3), got (%s, %s, %d)", shortest, longest, count)
+	}
+
+	// Skipped nodes are not taken into account
+	root.AddSkipNodeFunc(func(n *binarytree.Node[int]) bool {
+		return n.Value == 3
+	})
+	min, max, count = binarytree.Stats(root, less)
+	if min != 8 || max != 14 || count != 4 {
+		t.Fatalf("want (8, 14, 4), got (%d, %d, %d)", min, max, count)
+	}
+
+	// Skipped root yields zero values
+	root.AddSkipNodeFunc(func(n *binarytree.Node[int]) bool {
+		return n.Value == 8
+	})
+	min, max, count = binarytree.Stats(root, less)
+	if min != 0 || max != 0 || count != 0 {
+		t.Fatalf("want zero values for skipped root, got (%d, %d, %d)", min, max, count)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	// Our test trees
+	//
+	//     __1           2__
+	//    /   \         /   \
+	//   3     2       1     3
+	//  /               \     \
+	// 5                 4     7
+	//
+	a := binarytree.Build(1).Left(3).Left(5).Up().Up().Right(2).Root()
+	b := binarytree.Build(2).Left(1).Right(4).Up().Up().Right(3).Right(7).Root()
+	aBefore := a.Clone()
+	bBefore := b.Clone()
+
+	add := func(x, y int) int { return x + y }
+	merged := binarytree.Merge(a, b, add)
+
+	// The merged tree
+	//
+	//     __3__
+	//    /     \
+	//   4       5
+	//  / \       \
+	// 5   4       7
+	//
+	want := binarytree.Build(3).Left(4).Left(5).Up().Right(4).Up().Up().Right(5).Right(7).Root()
+	if !binarytree.Equal(merged, want) {
+		t.Fatal("unexpected merged tree")
+	}
+
+	if merged.Left.Left.Parent() != merged.Left {
+		t.Fatal("merged nodes should have parent links")
+	}
+
+	// The input trees are not modified
+	if !binarytree.Equal(a, aBefore) || !binarytree.Equal(b, bBefore) {
+		t.Fatal("input trees should not be modified")
+	}
+
+	// The merged tree does not share nodes with the input trees
+	merged.Left.Left.Value = 42
+	if a.Left.Left.Value != 5 {
+		t.Fatal("merged tree should not share nodes with the input trees")
+	}
+
+	// Merging with an empty tree copies the other tree
+	if got := binarytree.Merge(nil, b, add); !binarytree.Equal(got, b) || got == b {
+		t.Fatal("merging with an empty tree should copy the other tree")
+	}
+
+	if got := binarytree.Merge[int](nil, nil, add); got != nil {
+		t.Fatal("merging empty trees should yield an empty tree")
+	}
+}
+
+func TestSum(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	if got := binarytree.Sum(root); got != 15 {
+		t.Fatalf("want sum 15, got %d", got)
+	}
+
+	if got := binarytree.Sum[int](nil); got != 0 {
+		t.Fatalf("want sum 0 for empty tree, got %d", got)
+	}
+
+	floats := binarytree.Build(1.5).Left(2.25).Up().Right(-0.75).Root()
+	if got := binarytree.Sum(floats); got != 3.0 {
+		t.Fatalf("want sum 3.0, got %f", got)
+	}
+
+	// Integer sums wrap around on overflow
+	small := binarytree.Build(uint8(200)).Left(uint8(100)).Root()
+	if got := binarytree.Sum(small); got != 44 {
+		t.Fatalf("want wrapped sum 44, got %d", got)
+	}
+}
+
+func TestWidthSpans(t *testing.T) {
+	// Our test tree
+	//
+	//       __1__
+	//      /     \
+	//     2       3
+	//    /         \
+	//   4           5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Up().Right(3).Right(5).Root()
+
+	want := []int{1, 2, 4}
+	if got := root.WidthSpans(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want width spans %v, got %v", want, got)
+	}
+
+	if got := root.Width(); got != 4 {
+		t.Fatalf("want width 4, got %d", got)
+	}
+
+	// A right-skewed chain, which is deep enough for the naive
+	// position indices to overflow, ending with two leaves.
+	const depth = 200
+	root = binarytree.NewNode(0)
+	node := root
+	for i := 1; i < depth; i++ {
+		node = node.InsertRight(i)
+	}
+	node.InsertLeft(depth)
+	node.InsertRight(depth + 1)
+
+	spans := root.WidthSpans()
+	if len(spans) != depth+1 {
+		t.Fatalf("want %d levels, got %d", depth+1, len(spans))
+	}
+
+	for i, span := range spans[:depth] {
+		if span != 1 {
+			t.Fatalf("want width 1 at level %d, got %d", i, span)
+		}
+	}
+
+	if got := root.Width(); got != 2 {
+		t.Fatalf("want width 2, got %d", got)
+	}
+}
+
+func TestWriteDotPlain(t *testing.T) {
+	// Our test tree
+	//
+	//    1
+	//   / \
+	//  2   3
+	//
+	root := binarytree.Build(1).Left(2).Up().Right(3).Root()
+
+	var buf bytes.Buffer
+	if err := root.WriteDotWithOptions(&buf, binarytree.DotOptions{Plain: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	if strings.Contains(output, "shape=record") || strings.Contains(output, "<v>") {
+		t.Fatal("plain dot output should not contain records")
+	}
+
+	if strings.Contains(output, ":l ->") || strings.Contains(output, ":v") {
+		t.Fatal("plain dot output should not contain ports")
+	}
+
+	edges := regexp.MustCompile(`(?m)^\t\d+ -> \d+$`).FindAllString(output, -1)
+	if len(edges) != 2 {
+		t.Fatalf("want 2 plain edges, got %d", len(edges))
+	}
+
+	if !strings.Contains(output, `[label="1" ]`) {
+		t.Fatal("missing plain label of root node")
+	}
+
+	// The default output uses records
+	buf.Reset()
+	if err := root.WriteDot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "shape=record") {
+		t.Fatal("default dot output should use records")
+	}
+}
+
+func TestWriteDotWithOptions(t *testing.T) {
+	// Our test tree
+	//
+	//    1
+	//   / \
+	//  2   3
+	//
+	root := binarytree.Build(1).Left(2).Up().Right(3).Root()
+
+	var buf bytes.Buffer
+	if err := root.WriteDot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	wantHeader := "digraph {\n\tnode [color=lightblue fillcolor=lightblue fontcolor=black shape=record style=\"filled, rounded\"]\n"
+	if !strings.HasPrefix(buf.String(), wantHeader) {
+		t.Fatalf("want default header %q, got %q", wantHeader, buf.String())
+	}
+
+	buf.Reset()
+	opts := binarytree.DotOptions{
+		RankDir:   "LR",
+		NodeShape: "Mrecord",
+		NodeColor: "#ff8800",
+		GraphName: "my tree",
+	}
+	if err := root.WriteDotWithOptions(&buf, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	wantHeader = "digraph \"my tree\" {\n\trankdir=LR\n\tnode [color=\"#ff8800\" fillcolor=\"#ff8800\" fontcolor=black shape=Mrecord style=\"filled, rounded\"]\n"
+	if !strings.HasPrefix(buf.String(), wantHeader) {
+		t.Fatalf("want header %q, got %q", wantHeader, buf.String())
+	}
+
+	// Plain nodes with a custom shape
+	buf.Reset()
+	opts = binarytree.DotOptions{Plain: true, NodeShape: "circle", GraphName: "tree"}
+	if err := root.WriteDotWithOptions(&buf, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	wantHeader = "digraph tree {\n\tnode [color=lightblue fillcolor=lightblue fontcolor=black shape=circle style=filled]\n"
+	if !strings.HasPrefix(buf.String(), wantHeader) {
+		t.Fatalf("want header %q, got %q", wantHeader, buf.String())
+	}
+}
+
+func TestWriteDotNilPlaceholders(t *testing.T) {
+	// Our test tree
+	//
+	//    __1__
+	//   /     \
+	//  2       3
+	//   \     /
+	//    4   5
+	//
+	root := binarytree.Build(1).Left(2).Right(4).Up().Up().Right(3).Left(5).Root()
+
+	var buf bytes.Buffer
+	if err := root.WriteDot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(buf.String(), "invis") || strings.Contains(buf.String(), "ordering") {
+		t.Fatal("default dot output should not contain placeholders")
+	}
+
+	for _, plain := range []bool{false, true} {
+		buf.Reset()
+		opts := binarytree.DotOptions{Plain: plain, NilPlaceholders: true}
+		if err := root.WriteDotWithOptions(&buf, opts); err != nil {
+			t.Fatal(err)
+		}
+
+		output := buf.String()
+		if !strings.Contains(output, "\tordering=out\n") {
+			t.Fatal("missing ordering=out in dot output")
+		}
+
+		nodes := regexp.MustCompile(`(?m)^\tnil_\d+_[lr] \[label="" style=invis\]$`).FindAllString(output, -1)
+		if len(nodes) != 2 {
+			t.Fatalf("want 2 placeholder nodes, got %d", len(nodes))
+		}
+
+		// The placeholders are emitted in place of the missing
+		// edges, i.e. before the right edge of node (2), and after
+		// the left edge of node (3)
+		edges := regexp.MustCompile(`(?m)^\t[23](:[lr])? -> (\S+)`).FindAllStringSubmatch(output, -1)
+		got := make([]string, 0)
+		for _, edge := range edges {
+			got = append(got, edge[2])
+		}
+
+		want := []string{"nil_2_l", "4", "5", "nil_3_r"}
+		if !plain {
+			want = []string{"nil_2_l", "4:v", "5:v", "nil_3_r"}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("want edges to %v, got %v", want, got)
+		}
+	}
+}
+
+func TestMaxWidth(t *testing.T) {
+	// Our test tree
+	//
+	//     __1__
+	//    /     \
+	//   2       3
+	//  / \     / \
+	// 4   5   6   7
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Left(6).Up().Right(7).Root()
+
+	if got := root.MaxWidth(); got != 4 {
+		t.Fatalf("want max width 4, got %d", got)
+	}
+
+	// Skipped sub-trees are excluded
+	root.AddSkipNodeFunc(func(n *binarytree.Node[int]) bool {
+		return n.Value == 3
+	})
+
+	if got := root.MaxWidth(); got != 2 {
+		t.Fatalf("want max width 2, got %d", got)
+	}
+
+	leaf := binarytree.NewNode(1)
+	if got := leaf.MaxWidth(); got != 1 {
+		t.Fatalf("want max width 1, got %d", got)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	groups := binarytree.GroupBy(root, func(v int) string {
+		if v%2 == 0 {
+			return "even"
+		}
+		return "odd"
+	})
+
+	want := map[string][]int{
+		"even": {2, 4},
+		"odd":  {1, 5, 3},
+	}
+
+	if !reflect.DeepEqual(groups, want) {
+		t.Fatalf("want groups %v, got %v", want, groups)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	levels := root.Levels()
+	if levels[0][0] != root {
+		t.Fatal("root node should be at level 0")
+	}
+
+	result := make([][]int, 0)
+	for _, level := range levels {
+		values := make([]int, 0)
+		for _, node := range level {
+			values = append(values, node.Value)
+		}
+		result = append(result, values)
+	}
+
+	want := [][]int{{1}, {2, 3}, {4, 5}}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("want levels %v, got %v", want, result)
+	}
+}
+
+func TestNodesAtLevel(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \     \
+	// 4   5     6
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Right(6).Root()
+
+	tests := []struct {
+		level int
+		want  int
+	}{
+		{level: -1, want: 0},
+		{level: 0, want: 1},
+		{level: 1, want: 2},
+		{level: 2, want: 3},
+		{level: 3, want: 0},
+	}
+
+	for _, test := range tests {
+		if got := root.NodesAtLevel(test.level); got != test.want {
+			t.Fatalf("want %d nodes at level %d, got %d", test.want, test.level, got)
+		}
+	}
+
+	// The deepest level is never empty
+	if got := root.NodesAtLevel(root.Height()); got != 3 {
+		t.Fatalf("want 3 nodes at the deepest level, got %d", got)
+	}
+
+	// Levels are relative to the node
+	if got := root.Left.NodesAtLevel(1); got != 2 {
+		t.Fatalf("want 2 nodes at level 1 of node (2), got %d", got)
+	}
+
+	// Consistent with Levels
+	for i, level := range root.Levels() {
+		if got := root.NodesAtLevel(i); got != len(level) {
+			t.Fatalf("want %d nodes at level %d, got %d", len(level), i, got)
+		}
+	}
+}
+
+func TestAllPaths(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \     \
+	// 4   5     6
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Right(6).Root()
+
+	values := func(paths [][]*binarytree.Node[int]) [][]int {
+		result := make([][]int, 0)
+		for _, path := range paths {
+			items := make([]int, 0)
+			for _, node := range path {
+				items = append(items, node.Value)
+			}
+			result = append(result, items)
+		}
+		return result
+	}
+
+	paths := root.AllPaths()
+	want := [][]int{{1, 2, 4}, {1, 2, 5}, {1, 3, 6}}
+	if got := values(paths); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want paths %v, got %v", want, got)
+	}
+
+	// Paths do not share their backing arrays
+	paths[0][1] = root
+	want = [][]int{{1, 1, 4}, {1, 2, 5}, {1, 3, 6}}
+	if got := values(paths); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want paths %v, got %v", want, got)
+	}
+
+	// A single node yields a single path
+	leaf := binarytree.NewNode(42)
+	want = [][]int{{42}}
+	if got := values(leaf.AllPaths()); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want paths %v, got %v", want, got)
+	}
+}
+
+func TestPathTo(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \     \
+	// 4   5     6
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Right(6).Root()
+	five := root.Left.Right
+
+	path, ok := root.PathTo(five)
+	if !ok {
+		t.Fatal("node (5) should be reachable")
+	}
+
+	want := []*binarytree.Node[int]{root, root.Left, five}
+	if !reflect.DeepEqual(path, want) {
+		t.Fatal("unexpected path to node (5)")
+	}
+
+	// Path to the node itself
+	path, ok = root.PathTo(root)
+	if !ok || len(path) != 1 || path[0] != root {
+		t.Fatal("path to the node itself should contain only the node")
+	}
+
+	// Nodes are compared by identity
+	if _, ok := root.PathTo(binarytree.NewNode(5)); ok {
+		t.Fatal("node from another tree should not be reachable")
+	}
+
+	// Ancestors are not reachable from their descendants
+	if _, ok := root.Left.PathTo(root.Right); ok {
+		t.Fatal("node (3) should not be reachable from node (2)")
+	}
+}
+
+func TestInsertBST(t *testing.T) {
+	// Build the following BST
+	//
+	//      ____8
+	//     /     \
+	//    3__     10__
+	//   /   \        \
+	//  1     6        14
+	//       / \      /
+	//      4   7    13
+	//
+	root := binarytree.NewNode(8)
+	for _, v := range []int{3, 10, 1, 6, 14, 4, 7, 13} {
+		node := root.InsertBST(v, binarytree.IntComparator)
+		if node.Value != v {
+			t.Fatalf("want inserted node (%d), got (%d)", v, node.Value)
+		}
+
+		if !root.IsBinarySearchTree(binarytree.IntComparator) {
+			t.Fatalf("tree should be BST after inserting (%d)", v)
+		}
+	}
+
+	eq := func(a, b int) bool { return a == b }
+	want := []int{8, 3, 1, 6, 4, 7, 10, 14, 13}
+	if !root.SequenceEqual(binarytree.PreOrder, want, eq) {
+		t.Fatal("unexpected BST structure")
+	}
+
+	// Duplicate values go to the right sub-tree
+	dup := root.InsertBST(6, binarytree.IntComparator)
+	if dup.Parent().Value != 7 || dup.Parent().Left != dup {
+		t.Fatal("duplicate (6) should be the left child of node (7)")
+	}
+
+	if !root.IsBinarySearchTree(binarytree.IntComparator) {
+		t.Fatal("tree should be BST after inserting a duplicate")
+	}
+
+	// Cached bounds are maintained
+	root.RecomputeBounds(binarytree.IntComparator)
+	root.InsertBST(20, binarytree.IntComparator)
+	root.InsertBST(0, binarytree.IntComparator)
+	if lo, hi, ok := root.Bounds(); !ok || lo != 0 || hi != 20 {
+		t.Fatalf("want bounds [0, 20], got [%d, %d]", lo, hi)
+	}
+
+	if lo, hi, ok := root.Right.Bounds(); !ok || lo != 10 || hi != 20 {
+		t.Fatalf("want bounds [10, 20] for node (10), got [%d, %d]", lo, hi)
+	}
+
+	// Inserting into a sub-tree updates the bounds of its ancestors
+	root = binarytree.NewNode(5)
+	root.InsertBST(3, binarytree.IntComparator)
+	root.InsertBST(8, binarytree.IntComparator)
+	root.RecomputeBounds(binarytree.IntComparator)
+	root.Right.InsertBST(20, binarytree.IntComparator)
+	if lo, hi, ok := root.Bounds(); !ok || lo != 3 || hi != 20 {
+		t.Fatalf("want bounds [3, 20], got [%d, %d]", lo, hi)
+	}
+
+	if root.InRange(3, 8, binarytree.IntComparator) {
+		t.Fatal("tree should not be in range [3, 8]")
+	}
+}
+
+func TestGenerateTree(t *testing.T) {
+	makeValue := func(data []byte) int {
+		value := 0
+		for _, b := range data {
+			value = value<<8 | int(b)
+		}
+		return value
+	}
+
+	if root := binarytree.GenerateTree(nil, makeValue); root != nil {
+		t.Fatal("expected nil tree for empty data")
+	}
+
+	// Generates the following tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  /
+	// 4
+	//
+	data := []byte{0x07, 1, 0x05, 2, 0x04, 3, 0x04, 4}
+	root := binarytree.GenerateTree(data, makeValue)
+	want := binarytree.Build(1).Left(2).Left(4).Up().Up().Right(3).Root()
+	if !binarytree.Equal(root, want) {
+		t.Fatal("unexpected generated tree")
+	}
+
+	if root.Left.Left.Parent() != root.Left {
+		t.Fatal("generated nodes should have parent links")
+	}
+}
+
+func FuzzClone(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0x07, 1, 0x05, 2, 0x04, 3, 0x04, 4})
+	f.Add([]byte{0x0b, 1, 2, 0x01, 0x02, 0x03, 0xff, 0xfe, 0xfd})
+
+	makeValue := func(data []byte) int {
+		value := 0
+		for _, b := range data {
+			value = value<<8 | int(b)
+		}
+		return value
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		root := binarytree.GenerateTree(data, makeValue)
+		if root == nil {
+			return
+		}
+
+		clone := root.Clone()
+		if !binarytree.Equal(root, clone) {
+			t.Fatal("clone should be equal to the original tree")
+		}
+	})
+}
+
+func TestFromSortedSlice(t *testing.T) {
+	if root := binarytree.FromSortedSlice([]int{}); root != nil {
+		t.Fatal("expected nil tree for empty slice")
+	}
+
+	for size := 1; size <= 64; size++ {
+		values := make([]int, size)
+		for i := range values {
+			values[i] = i
+		}
+
+		root := binarytree.FromSortedSlice(values)
+		if root.Size() != size {
+			t.Fatalf("want tree of size %d, got %d", size, root.Size())
+		}
+
+		if !root.IsBalancedTree() {
+			t.Fatalf("tree of size %d should be balanced", size)
+		}
+
+		if !root.IsBinarySearchTree(binarytree.IntComparator) {
+			t.Fatalf("tree of size %d should be BST", size)
+		}
+
+		eq := func(a, b int) bool { return a == b }
+		if !root.SequenceEqual(binarytree.InOrder, values, eq) {
+			t.Fatalf("in-order walk of tree of size %d should yield the values", size)
+		}
+
+		if root.Min().Parent() == nil && size > 1 {
+			t.Fatalf("nodes of tree of size %d should have parent links", size)
+		}
+	}
+
+	// Builds the following tree
+	//
+	//     __b
+	//    /   \
+	//   a     c
+	//
+	root := binarytree.FromSortedSlice([]string{"a", "b", "c"})
+	want := binarytree.Build("b").Left("a").Up().Right("c").Root()
+	if !binarytree.Equal(root, want) {
+		t.Fatal("unexpected tree built from sorted slice")
+	}
+}
+
+func TestBalanceBST(t *testing.T) {
+	// A degenerate BST built from ascending values
+	root := binarytree.NewNode(1)
+	for v := 2; v <= 100; v++ {
+		root.InsertBST(v, binarytree.IntComparator)
+	}
+
+	if root.IsBalancedTree() {
+		t.Fatal("degenerate tree should not be balanced")
+	}
+
+	balanced := root.BalanceBST(binarytree.IntComparator)
+	if !balanced.IsBalancedTree() {
+		t.Fatal("tree should be balanced")
+	}
+
+	if !balanced.IsBinarySearchTree(binarytree.IntComparator) {
+		t.Fatal("tree should be BST")
+	}
+
+	if got := balanced.Height(); got != 6 {
+		t.Fatalf("want height 6, got %d", got)
+	}
+
+	if !reflect.DeepEqual(balanced.ToSlice(binarytree.InOrder), root.ToSlice(binarytree.InOrder)) {
+		t.Fatal("balanced tree should hold the same values")
 	}
 
-	// A perfect binary tree
+	// The original tree is not modified
+	if root.Height() != 99 {
+		t.Fatal("original tree should not be modified")
+	}
+
+	// Values of a tree, which is not a BST, are sorted
+	unordered := binarytree.Build(1).Left(2).Up().Right(3).Root()
+	balanced = unordered.BalanceBST(binarytree.IntComparator)
+	if !balanced.IsBinarySearchTree(binarytree.IntComparator) {
+		t.Fatal("tree should be BST")
+	}
+}
+
+func TestRotate(t *testing.T) {
+	// Our test tree
 	//
-	//     __1__
-	//    /     \
-	//   2       3
-	//  / \     / \
-	// 4   5   6   7
+	//      ____8
+	//     /     \
+	//    3__     10__
+	//   /   \        \
+	//  1     6        14
+	//       / \      /
+	//      4   7    13
 	//
-	root = binarytree.NewNode(1)
-	two = root.InsertLeft(2)
-	two.InsertLeft(4)
-	two.InsertRight(5)
-	three = root.InsertRight(3)
-	three.InsertLeft(6)
-	three.InsertRight(7)
-
-	if !root.IsPerfectTree() {
-		t.Fatal("tree should be perfect")
+	root := binarytree.NewNode(8)
+	for _, v := range []int{3, 10, 1, 6, 14, 4, 7, 13} {
+		root.InsertBST(v, binarytree.IntComparator)
 	}
+	want := root.ToSlice(binarytree.InOrder)
 
-	// A non-perfect binary tree
+	// Rotate the sub-tree of node (3) to the left
 	//
-	//     1
-	//    /
-	//   2
-	//  /
-	// 3
+	//        ____8
+	//       /     \
+	//      6       10__
+	//     / \          \
+	//    3   7         14
+	//   / \           /
+	//  1   4         13
 	//
-	root = binarytree.NewNode(1)
-	two = root.InsertLeft(2)
-	two.InsertLeft(3)
+	three := root.Left
+	six := three.RotateLeft()
+	if six.Value != 6 || root.Left != six || six.Parent() != root {
+		t.Fatal("node (6) should be the new left child of the root")
+	}
 
-	if root.IsPerfectTree() {
-		t.Fatal("tree should not be perfect")
+	if three.Parent() != six || six.Left != three || three.Right.Value != 4 || three.Right.Parent() != three {
+		t.Fatal("unexpected links after left rotation")
 	}
 
-	// A non-perfect binary tree
-	//
-	//   1__
-	//  /   \
-	// 2     3
-	//      / \
-	//     4   5
-	root = binarytree.NewNode(1)
-	root.InsertLeft(2)
-	three = root.InsertRight(3)
-	three.InsertLeft(4)
-	three.InsertRight(5)
+	if got := root.ToSlice(binarytree.InOrder); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want in-order %v after left rotation, got %v", want, got)
+	}
 
-	if root.IsPerfectTree() {
-		t.Fatal("tree should not be perfect")
+	// Rotating back to the right restores the tree
+	if got := six.RotateRight(); got != three || root.Left != three || three.Parent() != root {
+		t.Fatal("node (3) should be the left child of the root again")
 	}
 
-	// A perfect binary tree with a single root node
-	root = binarytree.NewNode(1)
-	if !root.IsPerfectTree() {
-		t.Fatal("tree should be perfect")
+	if !root.SequenceEqual(binarytree.PreOrder, []int{8, 3, 1, 6, 4, 7, 10, 14, 13}, func(a, b int) bool { return a == b }) {
+		t.Fatal("right rotation should restore the tree")
+	}
+
+	// Rotating the root to the right
+	newRoot := root.RotateRight()
+	if newRoot.Value != 3 || newRoot.Parent() != nil || root.Parent() != newRoot {
+		t.Fatal("node (3) should be the new root")
+	}
+
+	if got := newRoot.ToSlice(binarytree.InOrder); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want in-order %v after right rotation, got %v", want, got)
+	}
+
+	if !newRoot.IsBinarySearchTree(binarytree.IntComparator) {
+		t.Fatal("tree should be BST after rotations")
+	}
+
+	// Rotations without a pivot are no-ops
+	leaf := binarytree.NewNode(1)
+	if leaf.RotateLeft() != leaf || leaf.RotateRight() != leaf {
+		t.Fatal("rotating a leaf should be a no-op")
 	}
 }
 
-func TestIsBinarySearchTree(t *testing.T) {
-	// A valid BST
-	//
-	//    2
-	//   / \
-	//  1   3
-	//
-	root := binarytree.NewNode(2)
-	root.InsertLeft(1)
-	root.InsertRight(3)
+func TestInsertAVL(t *testing.T) {
+	// Ascending values would produce a degenerate BST
+	root := binarytree.NewNode(0)
+	for v := 1; v < 1000; v++ {
+		root = root.InsertAVL(v, binarytree.IntComparator)
+
+		if root.Parent() != nil {
+			t.Fatalf("root should have no parent after inserting (%d)", v)
+		}
+	}
+
+	if !root.IsBalancedTree() {
+		t.Fatal("tree should be balanced")
+	}
 
 	if !root.IsBinarySearchTree(binarytree.IntComparator) {
 		t.Fatal("tree should be BST")
 	}
 
-	// Invalid BST
-	//
-	//    1
-	//   / \
-	//  2   3
-	//
-	root = binarytree.NewNode(1)
-	root.InsertLeft(2)
-	root.InsertRight(3)
+	if got := root.Size(); got != 1000 {
+		t.Fatalf("want 1000 nodes, got %d", got)
+	}
 
-	if root.IsBinarySearchTree(binarytree.IntComparator) {
-		t.Fatal("tree should not be BST")
+	if got := root.Height(); got > 14 {
+		t.Fatalf("want height at most 14, got %d", got)
 	}
 
-	// Invalid BST
+	// Zig-zag insertions require double rotations
 	//
-	//      1
-	//     / \
-	//    2   3
-	//   /
-	//  4
+	//     3        2
+	//    /        / \
+	//   1   =>   1   3
+	//    \
+	//     2
 	//
+	root = binarytree.NewNode(3)
+	root = root.InsertAVL(1, binarytree.IntComparator)
+	root = root.InsertAVL(2, binarytree.IntComparator)
+	want := binarytree.Build(2).Left(1).Up().Right(3).Root()
+	if !binarytree.Equal(root, want) {
+		t.Fatal("unexpected tree after left-right rotation")
+	}
+
 	root = binarytree.NewNode(1)
-	root.InsertRight(3)
-	two := root.InsertLeft(2)
-	two.InsertLeft(4)
+	root = root.InsertAVL(3, binarytree.IntComparator)
+	root = root.InsertAVL(2, binarytree.IntComparator)
+	if !binarytree.Equal(root, want) {
+		t.Fatal("unexpected tree after right-left rotation")
+	}
 
-	if root.IsBinarySearchTree(binarytree.IntComparator) {
-		t.Fatal("tree should not be BST")
+	// Cached bounds are maintained
+	root = binarytree.NewNode(50)
+	root.RecomputeBounds(binarytree.IntComparator)
+	for v := 49; v >= 0; v-- {
+		root = root.InsertAVL(v, binarytree.IntComparator)
 	}
 
-	// A valid BST
+	walkFunc := func(node *binarytree.Node[int]) error {
+		lo, hi, _ := node.Bounds()
+		if lo != node.Min().Value || hi != node.Max().Value {
+			t.Fatalf("unexpected bounds [%d, %d] of node (%d)", lo, hi, node.Value)
+		}
+		return nil
+	}
+
+	if err := root.WalkPreOrder(walkFunc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecomputeHeights(t *testing.T) {
+	// checkHeights verifies that the height of each node is
+	// consistent with the heights of its children.
+	checkHeights := func(root *binarytree.Node[int]) {
+		t.Helper()
+		walkFunc := func(node *binarytree.Node[int]) error {
+			want := 0
+			for _, child := range []*binarytree.Node[int]{node.Left, node.Right} {
+				if child != nil && child.Height()+1 > want {
+					want = child.Height() + 1
+				}
+			}
+			if got := node.Height(); got != want {
+				t.Fatalf("want height %d of node (%d), got %d", want, node.Value, got)
+			}
+			return nil
+		}
+
+		if err := root.WalkPostOrder(walkFunc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Our test tree
 	//
-	//     ______8
-	//    /       \
-	//   3__       10___
-	//  /   \           \
-	// 1     6          _14
-	//      / \        /
-	//     4   7      13
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
 	//
-	root = binarytree.NewNode(8)
-	three := root.InsertLeft(3)
-	three.InsertLeft(1)
-	six := three.InsertRight(6)
-	six.InsertLeft(4)
-	six.InsertRight(7)
-	ten := root.InsertRight(10)
-	fourteen := ten.InsertRight(14)
-	fourteen.InsertLeft(13)
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	root.RecomputeHeights()
+	checkHeights(root)
+	if got := root.Height(); got != 2 {
+		t.Fatalf("want height 2, got %d", got)
+	}
 
-	if !root.IsBinarySearchTree(binarytree.IntComparator) {
-		t.Fatal("tree should be BST")
+	// Insertions maintain the cache
+	root.Left.Left.InsertLeft(6).InsertRight(7)
+	checkHeights(root)
+	if got := root.Height(); got != 4 {
+		t.Fatalf("want height 4, got %d", got)
 	}
 
-	// A tree with a single root node is a valid BST
-	root = binarytree.NewNode(1)
-	if !root.IsBinarySearchTree(binarytree.IntComparator) {
+	// Removals maintain the cache
+	if !root.Left.Left.Remove() {
+		t.Fatal("node (4) should be removed")
+	}
+	checkHeights(root)
+	if got := root.Height(); got != 2 {
+		t.Fatalf("want height 2, got %d", got)
+	}
+
+	// Rotations maintain the cache
+	root.Left.RotateRight()
+	checkHeights(root)
+
+	// Direct mutations bypass the cache until recomputed
+	root.Right.Right = binarytree.Build(8).Left(9).Left(10).Root()
+	if got := root.Height(); got != 2 {
+		t.Fatalf("want stale height 2, got %d", got)
+	}
+
+	root.RecomputeHeights()
+	checkHeights(root)
+	if got := root.Height(); got != 4 {
+		t.Fatalf("want height 4, got %d", got)
+	}
+
+	// BST operations maintain the cache
+	bst := binarytree.NewNode(50)
+	bst.RecomputeHeights()
+	for v := 0; v < 100; v++ {
+		bst = bst.InsertAVL((v*37)%100, binarytree.IntComparator)
+	}
+	checkHeights(bst)
+
+	for v := 0; v < 100; v += 3 {
+		bst, _ = bst.DeleteBST(v, binarytree.IntComparator)
+		checkHeights(bst)
+	}
+
+	if !bst.IsBinarySearchTree(binarytree.IntComparator) {
 		t.Fatal("tree should be BST")
 	}
+}
 
-	// A valid BST
+func BenchmarkInsertAVLCachedHeights(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		root := binarytree.NewNode(0)
+		root.RecomputeHeights()
+		for v := 1; v < 10000; v++ {
+			root = root.InsertAVL(v, binarytree.IntComparator)
+		}
+	}
+}
+
+func TestFromPreIn(t *testing.T) {
+	// Rebuilds our test tree
 	//
-	//   B
+	//     __1
+	//    /   \
+	//   2     3
 	//  / \
-	// A   C
+	// 4   5
 	//
-	str_root := binarytree.NewNode("B")
-	str_root.InsertLeft("A")
-	str_root.InsertRight("C")
+	root, err := binarytree.FromPreIn([]int{1, 2, 4, 5, 3}, []int{4, 2, 5, 1, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	if !str_root.IsBinarySearchTree(binarytree.StringComparator) {
-		t.Fatal("tree should be BST")
+	want := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	if !binarytree.Equal(root, want) {
+		t.Fatal("unexpected tree rebuilt from traversals")
 	}
 
-	// Invalid BST
-	//
-	//     A
-	//    / \
-	//   B   C
-	//  /
-	// D
-	str_root = binarytree.NewNode("A")
-	str_root.InsertRight("C")
-	b := str_root.InsertLeft("B")
-	b.InsertLeft("D")
+	if root.Left.Right.Parent() != root.Left {
+		t.Fatal("rebuilt nodes should have parent links")
+	}
 
-	if str_root.IsBinarySearchTree(binarytree.StringComparator) {
-		t.Fatal("tree should not be BST")
+	// Round-trip of a degenerate tree
+	degenerate := binarytree.Build(1).Right(2).Left(3).Right(4).Root()
+	root, err = binarytree.FromPreIn(degenerate.ToSlice(binarytree.PreOrder), degenerate.ToSlice(binarytree.InOrder))
+	if err != nil {
+		t.Fatal(err)
 	}
-}
 
-func TestNodeAttributes(t *testing.T) {
-	root := binarytree.NewNode(1)
+	if !binarytree.Equal(root, degenerate) {
+		t.Fatal("unexpected degenerate tree rebuilt from traversals")
+	}
 
-	if root.GetDotAttributes() != "" {
-		t.Fatal("node is expected to have no attributes")
+	// Empty traversals
+	root, err = binarytree.FromPreIn([]int{}, []int{})
+	if err != nil || root != nil {
+		t.Fatal("expected nil tree for empty traversals")
 	}
 
-	root.AddAttribute("color", "green")
+	// Inconsistent traversals
+	tests := []struct {
+		desc     string
+		preorder []int
+		inorder  []int
+	}{
+		{desc: "length mismatch", preorder: []int{1, 2}, inorder: []int{1}},
+		{desc: "missing value", preorder: []int{1, 2, 6}, inorder: []int{2, 1, 3}},
+		{desc: "duplicate value", preorder: []int{1, 1}, inorder: []int{1, 1}},
+		{desc: "out of place", preorder: []int{1, 2, 3}, inorder: []int{3, 1, 2}},
+	}
 
-	wantAttrs := "color=green"
-	gotAttrs := root.GetDotAttributes()
-	if gotAttrs != wantAttrs {
-		t.Fatal("node attributes mismatch")
+	for _, test := range tests {
+		_, err := binarytree.FromPreIn(test.preorder, test.inorder)
+		if !errors.Is(err, binarytree.ErrInconsistentTraversals) {
+			t.Fatalf("%s: want inconsistent traversals error, got %v", test.desc, err)
+		}
 	}
 }
 
-func TestWriteDot(t *testing.T) {
-	// Our test tree
+func TestFromPostIn(t *testing.T) {
+	// Rebuilds our test tree
 	//
-	//   1__
-	//  /   \
-	// 2     3
-	//      / \
-	//     4   5
-	root := binarytree.NewNode(1)
-	root.InsertLeft(2)
-	three := root.InsertRight(3)
-	three.InsertLeft(4)
-	three.InsertRight(5)
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root, err := binarytree.FromPostIn([]int{4, 5, 2, 3, 1}, []int{4, 2, 5, 1, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	var buf bytes.Buffer
-	if err := root.WriteDot(&buf); err != nil {
+	want := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	if !binarytree.Equal(root, want) {
+		t.Fatal("unexpected tree rebuilt from traversals")
+	}
+
+	if root.Left.Right.Parent() != root.Left {
+		t.Fatal("rebuilt nodes should have parent links")
+	}
+
+	// Round-trip of a degenerate tree
+	degenerate := binarytree.Build(1).Right(2).Left(3).Right(4).Root()
+	root, err = binarytree.FromPostIn(degenerate.ToSlice(binarytree.PostOrder), degenerate.ToSlice(binarytree.InOrder))
+	if err != nil {
 		t.Fatal(err)
 	}
 
-	output := buf.String()
-	if output == "" {
-		t.Fatal("got empty dot representation")
+	if !binarytree.Equal(root, degenerate) {
+		t.Fatal("unexpected degenerate tree rebuilt from traversals")
 	}
 
-	if !strings.HasPrefix(output, "digraph {") {
-		t.Fatal("missing dot prefix")
+	// Empty traversals
+	root, err = binarytree.FromPostIn([]int{}, []int{})
+	if err != nil || root != nil {
+		t.Fatal("expected nil tree for empty traversals")
 	}
 
-	if !strings.HasSuffix(output, "}\n") {
-		t.Fatal("missing dot suffix")
+	// Length mismatch
+	_, err = binarytree.FromPostIn([]int{1, 2}, []int{1})
+	var lengthErr *binarytree.LengthMismatchError
+	if !errors.As(err, &lengthErr) {
+		t.Fatalf("want length mismatch error, got %v", err)
+	}
+
+	if lengthErr.Traversal != "post-order" || lengthErr.Len != 2 || lengthErr.InOrderLen != 1 {
+		t.Fatalf("unexpected length mismatch error: %v", lengthErr)
+	}
+
+	if !errors.Is(err, binarytree.ErrInconsistentTraversals) {
+		t.Fatal("length mismatch error should wrap inconsistent traversals error")
+	}
+
+	// Missing value
+	_, err = binarytree.FromPostIn([]int{2, 3, 6}, []int{2, 1, 3})
+	var missingErr *binarytree.MissingValueError
+	if !errors.As(err, &missingErr) {
+		t.Fatalf("want missing value error, got %v", err)
+	}
+
+	if missingErr.Value != 6 {
+		t.Fatalf("want missing value 6, got %v", missingErr.Value)
+	}
+
+	if !errors.Is(err, binarytree.ErrInconsistentTraversals) {
+		t.Fatal("missing value error should wrap inconsistent traversals error")
+	}
+
+	// Out of place value
+	_, err = binarytree.FromPostIn([]int{3, 1, 2}, []int{1, 2, 3})
+	if !errors.Is(err, binarytree.ErrInconsistentTraversals) {
+		t.Fatalf("want inconsistent traversals error, got %v", err)
 	}
 }
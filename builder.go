@@ -0,0 +1,93 @@
+// Copyright (c) 2022 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer
+//    in this position and unchanged.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR(S) ``AS IS'' AND ANY EXPRESS OR
+// IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+// OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+// IN NO EVENT SHALL THE AUTHOR(S) BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+// NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+// THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package binarytree
+
+// Builder provides a fluent API for constructing binary trees.
+type Builder[T any] struct {
+	// root is the root node of the tree being built
+	root *Node[T]
+
+	// path contains the nodes from the root down to the node
+	// which is currently being built.
+	path []*Node[T]
+}
+
+// Build creates a new builder with a root node of the given value.
+//
+// Example usage:
+//
+//	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+func Build[T any](value T) *Builder[T] {
+	root := NewNode(value)
+	b := &Builder[T]{
+		root: root,
+		path: []*Node[T]{root},
+	}
+
+	return b
+}
+
+// current returns the node which is currently being built.
+func (b *Builder[T]) current() *Node[T] {
+	return b.path[len(b.path)-1]
+}
+
+// Left inserts a new left child to the current node and moves the
+// builder to it.
+func (b *Builder[T]) Left(value T) *Builder[T] {
+	left := b.current().InsertLeft(value)
+	b.path = append(b.path, left)
+
+	return b
+}
+
+// Right inserts a new right child to the current node and moves the
+// builder to it.
+func (b *Builder[T]) Right(value T) *Builder[T] {
+	right := b.current().InsertRight(value)
+	b.path = append(b.path, right)
+
+	return b
+}
+
+// Up moves the builder to the parent of the current node. Calling Up
+// while the builder is at the root node is a no-op.
+func (b *Builder[T]) Up() *Builder[T] {
+	if len(b.path) > 1 {
+		b.path = b.path[:len(b.path)-1]
+	}
+
+	return b
+}
+
+// Current returns the node the builder is currently at.
+func (b *Builder[T]) Current() *Node[T] {
+	return b.current()
+}
+
+// Root returns the root node of the built tree.
+func (b *Builder[T]) Root() *Node[T] {
+	return b.root
+}
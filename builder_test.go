@@ -0,0 +1,80 @@
+// Copyright (c) 2022 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//  1. Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer
+//     in this position and unchanged.
+//  2. Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in the
+//     documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR(S) “AS IS” AND ANY EXPRESS OR
+// IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+// OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+// IN NO EVENT SHALL THE AUTHOR(S) BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+// NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+// THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package binarytree_test
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/dnaeon/go-binarytree.v1"
+)
+
+func TestBuilder(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	if root.Size() != 5 {
+		t.Fatal("expected tree size should be 5")
+	}
+
+	result := make([]int, 0)
+	wantResult := []int{1, 2, 4, 5, 3}
+	walkFunc := func(node *binarytree.Node[int]) error {
+		result = append(result, node.Value)
+		return nil
+	}
+
+	if err := root.WalkPreOrder(walkFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(wantResult, result) {
+		t.Fatalf("want pre-order values %v, got %v", wantResult, result)
+	}
+}
+
+func TestBuilderUpAtRoot(t *testing.T) {
+	b := binarytree.Build(1).Up().Up()
+	if b.Current() != b.Root() {
+		t.Fatal("builder should stay at the root node")
+	}
+
+	b.Left(2).Up().Right(3)
+	if b.Current().Value != 3 {
+		t.Fatal("builder should be at node (3)")
+	}
+
+	root := b.Root()
+	if root.Left.Value != 2 || root.Right.Value != 3 {
+		t.Fatal("unexpected children of the root node")
+	}
+}
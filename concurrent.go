@@ -0,0 +1,131 @@
+// Copyright (c) 2022 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer
+//    in this position and unchanged.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR(S) ``AS IS'' AND ANY EXPRESS OR
+// IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+// OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+// IN NO EVENT SHALL THE AUTHOR(S) BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+// NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+// THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package binarytree
+
+import "sync"
+
+// ConcurrentTree is a wrapper, which guards a binary tree with a
+// read-write mutex, so that it can be safely walked and modified from
+// multiple goroutines. Walks and queries take the read lock, while
+// insertions take the write lock.
+//
+// The lock guards only the operations performed via the wrapper.
+// Accessing the fields of the underlying nodes directly, or calling
+// their methods, bypasses the lock. The functions passed to the
+// wrapper methods must not call the methods of the wrapper which take
+// the write lock, as this would deadlock.
+type ConcurrentTree[T any] struct {
+	// mu guards the tree
+	mu sync.RWMutex
+
+	// root is the root node of the guarded tree
+	root *Node[T]
+}
+
+// NewConcurrentTree creates a new wrapper guarding the tree rooted at
+// the given node.
+func NewConcurrentTree[T any](root *Node[T]) *ConcurrentTree[T] {
+	t := &ConcurrentTree[T]{
+		root: root,
+	}
+
+	return t
+}
+
+// Root returns the root node of the guarded tree.
+func (t *ConcurrentTree[T]) Root() *Node[T] {
+	return t.root
+}
+
+// InsertLeft inserts a new left child with the given value to the
+// given node, while holding the write lock.
+func (t *ConcurrentTree[T]) InsertLeft(node *Node[T], value T) *Node[T] {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return node.InsertLeft(value)
+}
+
+// InsertRight inserts a new right child with the given value to the
+// given node, while holding the write lock.
+func (t *ConcurrentTree[T]) InsertRight(node *Node[T], value T) *Node[T] {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return node.InsertRight(value)
+}
+
+// WalkInOrder performs an In-order walking of the tree, while holding
+// the read lock.
+func (t *ConcurrentTree[T]) WalkInOrder(walkFunc WalkFunc[T]) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.root.WalkInOrder(walkFunc)
+}
+
+// WalkPreOrder performs a Pre-order walking of the tree, while
+// holding the read lock.
+func (t *ConcurrentTree[T]) WalkPreOrder(walkFunc WalkFunc[T]) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.root.WalkPreOrder(walkFunc)
+}
+
+// WalkPostOrder performs a Post-order walking of the tree, while
+// holding the read lock.
+func (t *ConcurrentTree[T]) WalkPostOrder(walkFunc WalkFunc[T]) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.root.WalkPostOrder(walkFunc)
+}
+
+// WalkLevelOrder performs a Level-order walking of the tree, while
+// holding the read lock.
+func (t *ConcurrentTree[T]) WalkLevelOrder(walkFunc WalkFunc[T]) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.root.WalkLevelOrder(walkFunc)
+}
+
+// Size returns the size of the tree, while holding the read lock.
+func (t *ConcurrentTree[T]) Size() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.root.Size()
+}
+
+// FindNode searches for a node in the tree for which the predicate
+// returns true, while holding the read lock.
+func (t *ConcurrentTree[T]) FindNode(predicate FindFunc[T]) (*Node[T], bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.root.FindNode(predicate)
+}
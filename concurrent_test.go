@@ -0,0 +1,106 @@
+// Copyright (c) 2022 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//  1. Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer
+//     in this position and unchanged.
+//  2. Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in the
+//     documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR(S) “AS IS” AND ANY EXPRESS OR
+// IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+// OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+// IN NO EVENT SHALL THE AUTHOR(S) BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+// NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+// THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package binarytree_test
+
+import (
+	"sync"
+	"testing"
+
+	"gopkg.in/dnaeon/go-binarytree.v1"
+)
+
+func TestConcurrentTree(t *testing.T) {
+	tree := binarytree.NewConcurrentTree(binarytree.NewNode(0))
+
+	const writers = 4
+	const inserts = 100
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// Each writer grows its own chain below the root
+			node := tree.Root()
+			for j := 0; j < inserts; j++ {
+				if (i+j)%2 == 0 {
+					node = tree.InsertLeft(node, i*inserts+j)
+				} else {
+					node = tree.InsertRight(node, i*inserts+j)
+				}
+			}
+		}(i)
+	}
+
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < inserts; j++ {
+				walkFunc := func(node *binarytree.Node[int]) error {
+					_ = node.Value
+					return nil
+				}
+
+				if err := tree.WalkInOrder(walkFunc); err != nil {
+					t.Error(err)
+				}
+				if err := tree.WalkLevelOrder(walkFunc); err != nil {
+					t.Error(err)
+				}
+				tree.Size()
+				tree.FindNode(func(node *binarytree.Node[int]) bool {
+					return node.Value < 0
+				})
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if tree.Size() < 1+inserts {
+		t.Fatalf("want at least %d nodes, got %d", 1+inserts, tree.Size())
+	}
+
+	visited := 0
+	walkFunc := func(node *binarytree.Node[int]) error {
+		visited++
+		return nil
+	}
+
+	if err := tree.WalkPreOrder(walkFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tree.WalkPostOrder(walkFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	if visited != 2*tree.Size() {
+		t.Fatalf("want %d visited nodes, got %d", 2*tree.Size(), visited)
+	}
+}
@@ -1,5 +1,3 @@
 module gopkg.in/dnaeon/go-binarytree.v1
 
-go 1.19
-
-require gopkg.in/dnaeon/go-deque.v1 v1.0.0-20220924123127-c8d2565cae45
+go 1.23
@@ -0,0 +1,157 @@
+// Copyright (c) 2022 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer
+//    in this position and unchanged.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR(S) ``AS IS'' AND ANY EXPRESS OR
+// IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+// OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+// IN NO EVENT SHALL THE AUTHOR(S) BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+// NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+// THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package binarytree
+
+import "math/bits"
+
+// Heap is a priority queue backed by a complete binary tree. The
+// value, which compares as the smallest according to the comparator,
+// has the highest priority, so using a reversed comparator turns the
+// heap into a max-heap. Push and Pop are O(log n), since the position
+// of the last node is derived from the size of the heap.
+type Heap[T any] struct {
+	// root is the root node of the backing tree
+	root *Node[T]
+
+	// size is the number of values in the heap
+	size int
+
+	// cmp is the comparator used for ordering the values
+	cmp ComparatorFunc[T]
+}
+
+// NewHeap creates a new empty heap, which orders values using the
+// given comparator.
+func NewHeap[T any](cmp ComparatorFunc[T]) *Heap[T] {
+	h := &Heap[T]{
+		root: nil,
+		size: 0,
+		cmp:  cmp,
+	}
+
+	return h
+}
+
+// Len returns the number of values in the heap.
+func (h *Heap[T]) Len() int {
+	return h.size
+}
+
+// Push adds a new value to the heap.
+func (h *Heap[T]) Push(value T) {
+	h.size++
+	if h.root == nil {
+		h.root = NewNode(value)
+		return
+	}
+
+	// Attach the new node at the next position in level-order, and
+	// sift it up, until its parent has a higher priority. The
+	// backing tree never caches its bounds, so the values are
+	// swapped directly.
+	parent := h.nodeAt(h.size / 2)
+	node := NewNode(value)
+	if h.size%2 == 0 {
+		parent.InsertLeftNode(node)
+	} else {
+		parent.InsertRightNode(node)
+	}
+
+	for node.parent != nil && h.cmp(node.Value, node.parent.Value) < 0 {
+		node.Value, node.parent.Value = node.parent.Value, node.Value
+		node = node.parent
+	}
+}
+
+// nodeAt returns the node at the given 1-based position in
+// level-order. The bits of the position following the most
+// significant one encode the path from the root, where 0 stands for
+// the left child and 1 for the right child.
+func (h *Heap[T]) nodeAt(pos int) *Node[T] {
+	node := h.root
+	for bit := bits.Len(uint(pos)) - 2; bit >= 0; bit-- {
+		if pos&(1<<bit) == 0 {
+			node = node.Left
+		} else {
+			node = node.Right
+		}
+	}
+
+	return node
+}
+
+// Peek returns the value with the highest priority without removing
+// it from the heap. The bool result is false, if the heap is empty.
+func (h *Heap[T]) Peek() (T, bool) {
+	var empty T
+	if h.root == nil {
+		return empty, false
+	}
+
+	return h.root.Value, true
+}
+
+// Pop removes and returns the value with the highest priority from
+// the heap. The bool result is false, if the heap is empty.
+func (h *Heap[T]) Pop() (T, bool) {
+	var empty T
+	if h.root == nil {
+		return empty, false
+	}
+
+	top := h.root.Value
+	if h.size == 1 {
+		h.root = nil
+		h.size = 0
+		return top, true
+	}
+
+	last := h.nodeAt(h.size)
+	last.Remove()
+	h.size--
+
+	// Move the last value to the root and sift it down, until
+	// both children have a lower priority.
+	h.root.Value = last.Value
+	node := h.root
+	for {
+		next := node
+		if node.Left != nil && h.cmp(node.Left.Value, next.Value) < 0 {
+			next = node.Left
+		}
+		if node.Right != nil && h.cmp(node.Right.Value, next.Value) < 0 {
+			next = node.Right
+		}
+
+		if next == node {
+			break
+		}
+
+		node.Value, next.Value = next.Value, node.Value
+		node = next
+	}
+
+	return top, true
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2022 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//  1. Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer
+//     in this position and unchanged.
+//  2. Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in the
+//     documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR(S) “AS IS” AND ANY EXPRESS OR
+// IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+// OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+// IN NO EVENT SHALL THE AUTHOR(S) BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+// NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+// THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package binarytree_test
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"gopkg.in/dnaeon/go-binarytree.v1"
+)
+
+func TestHeap(t *testing.T) {
+	h := binarytree.NewHeap(binarytree.IntComparator)
+	if _, ok := h.Peek(); ok {
+		t.Fatal("empty heap should have no values")
+	}
+
+	if _, ok := h.Pop(); ok {
+		t.Fatal("empty heap should have no values")
+	}
+
+	values := []int{5, 3, 8, 1, 9, 2, 7, 3, 6, 4}
+	for _, v := range values {
+		h.Push(v)
+	}
+
+	if h.Len() != len(values) {
+		t.Fatalf("want heap length %d, got %d", len(values), h.Len())
+	}
+
+	if top, ok := h.Peek(); !ok || top != 1 {
+		t.Fatalf("want top value 1, got %d", top)
+	}
+
+	result := make([]int, 0)
+	for h.Len() > 0 {
+		v, ok := h.Pop()
+		if !ok {
+			t.Fatal("heap should not be empty")
+		}
+		result = append(result, v)
+	}
+
+	want := []int{1, 2, 3, 3, 4, 5, 6, 7, 8, 9}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("want popped values %v, got %v", want, result)
+	}
+
+	if _, ok := h.Pop(); ok {
+		t.Fatal("heap should be empty")
+	}
+}
+
+func TestMaxHeap(t *testing.T) {
+	reversed := func(a, b int) int {
+		return binarytree.IntComparator(b, a)
+	}
+
+	h := binarytree.NewHeap(reversed)
+	for _, v := range []int{2, 9, 4, 7} {
+		h.Push(v)
+	}
+
+	result := make([]int, 0)
+	for h.Len() > 0 {
+		v, _ := h.Pop()
+		result = append(result, v)
+	}
+
+	want := []int{9, 7, 4, 2}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("want popped values %v, got %v", want, result)
+	}
+}
+
+func TestHeapInterleaved(t *testing.T) {
+	h := binarytree.NewHeap(binarytree.IntComparator)
+	pending := make([]int, 0)
+
+	// Pop after every third push, and compare with the smallest
+	// pending value
+	for i := 0; i < 300; i++ {
+		v := (i * 7919) % 101
+		h.Push(v)
+		pending = append(pending, v)
+
+		if i%3 != 2 {
+			continue
+		}
+
+		slices.Sort(pending)
+		got, ok := h.Pop()
+		if !ok || got != pending[0] {
+			t.Fatalf("want popped value %d, got %d", pending[0], got)
+		}
+		pending = pending[1:]
+	}
+
+	if h.Len() != len(pending) {
+		t.Fatalf("want heap length %d, got %d", len(pending), h.Len())
+	}
+
+	slices.Sort(pending)
+	for _, want := range pending {
+		if got, _ := h.Pop(); got != want {
+			t.Fatalf("want popped value %d, got %d", want, got)
+		}
+	}
+}
+
+func BenchmarkHeap(b *testing.B) {
+	h := binarytree.NewHeap(binarytree.IntComparator)
+	for i := 0; i < 100000; i++ {
+		h.Push((i * 7919) % 100003)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Push(i % 100003)
+		h.Pop()
+	}
+}
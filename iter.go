@@ -0,0 +1,72 @@
+// Copyright (c) 2022 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer
+//    in this position and unchanged.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR(S) ``AS IS'' AND ANY EXPRESS OR
+// IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+// OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+// IN NO EVENT SHALL THE AUTHOR(S) BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+// NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+// THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package binarytree
+
+import (
+	"iter"
+)
+
+// seq returns an iterator over the nodes of the tree in the given
+// order.
+func (n *Node[T]) seq(order TraversalOrder) iter.Seq[*Node[T]] {
+	it := func(yield func(*Node[T]) bool) {
+		walkFunc := func(node *Node[T]) error {
+			if !yield(node) {
+				return ErrStopWalk
+			}
+			return nil
+		}
+
+		if err := stopWalk(n.walk(order, n.shouldSkipNode, walkFunc)); err != nil {
+			panic(err)
+		}
+	}
+
+	return it
+}
+
+// InOrder returns an iterator, which yields the nodes of the tree in
+// In-order - Left-Node-Right (LNR)
+func (n *Node[T]) InOrder() iter.Seq[*Node[T]] {
+	return n.seq(InOrder)
+}
+
+// PreOrder returns an iterator, which yields the nodes of the tree in
+// Pre-order - Node-Left-Right (NLR)
+func (n *Node[T]) PreOrder() iter.Seq[*Node[T]] {
+	return n.seq(PreOrder)
+}
+
+// PostOrder returns an iterator, which yields the nodes of the tree in
+// Post-order - Left-Right-Node (LRN)
+func (n *Node[T]) PostOrder() iter.Seq[*Node[T]] {
+	return n.seq(PostOrder)
+}
+
+// LevelOrder returns an iterator, which yields the nodes of the tree
+// in Level-order (Breadth-first).
+func (n *Node[T]) LevelOrder() iter.Seq[*Node[T]] {
+	return n.seq(LevelOrder)
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2022 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//  1. Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer
+//     in this position and unchanged.
+//  2. Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in the
+//     documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR(S) “AS IS” AND ANY EXPRESS OR
+// IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+// OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+// IN NO EVENT SHALL THE AUTHOR(S) BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+// NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+// THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package binarytree_test
+
+import (
+	"iter"
+	"reflect"
+	"testing"
+
+	"gopkg.in/dnaeon/go-binarytree.v1"
+)
+
+func TestIterators(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	tests := []struct {
+		name string
+		seq  iter.Seq[*binarytree.Node[int]]
+		want []int
+	}{
+		{name: "in-order", seq: root.InOrder(), want: []int{4, 2, 5, 1, 3}},
+		{name: "pre-order", seq: root.PreOrder(), want: []int{1, 2, 4, 5, 3}},
+		{name: "post-order", seq: root.PostOrder(), want: []int{4, 5, 2, 3, 1}},
+		{name: "level-order", seq: root.LevelOrder(), want: []int{1, 2, 3, 4, 5}},
+	}
+
+	for _, test := range tests {
+		result := make([]int, 0)
+		for node := range test.seq {
+			result = append(result, node.Value)
+		}
+
+		if !reflect.DeepEqual(result, test.want) {
+			t.Fatalf("want %s values %v, got %v", test.name, test.want, result)
+		}
+	}
+}
+
+func TestIteratorsBreak(t *testing.T) {
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	result := make([]int, 0)
+	for node := range root.PreOrder() {
+		if node.Value == 5 {
+			break
+		}
+		result = append(result, node.Value)
+	}
+
+	want := []int{1, 2, 4}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("want pre-order values %v, got %v", want, result)
+	}
+}
+
+func TestIteratorsSkipNodeFuncs(t *testing.T) {
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+	root.AddSkipNodeFunc(func(n *binarytree.Node[int]) bool {
+		return n.Value == 2
+	})
+
+	result := make([]int, 0)
+	for node := range root.InOrder() {
+		result = append(result, node.Value)
+	}
+
+	want := []int{1, 3}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("want in-order values %v, got %v", want, result)
+	}
+}
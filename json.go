@@ -0,0 +1,184 @@
+// Copyright (c) 2022 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer
+//    in this position and unchanged.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR(S) ``AS IS'' AND ANY EXPRESS OR
+// IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+// OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+// IN NO EVENT SHALL THE AUTHOR(S) BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+// NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+// THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package binarytree
+
+import (
+	"encoding/json"
+)
+
+// JSONOption is a function which configures the JSON representation
+// of a tree.
+type JSONOption func(opts *jsonOptions)
+
+// jsonOptions specifies what gets included in the JSON
+// representation of a tree.
+type jsonOptions struct {
+	// withID specifies whether to include the node ids
+	withID bool
+
+	// withMeta specifies whether to include the node metadata
+	withMeta bool
+
+	// withAttributes specifies whether to include the Dot attributes
+	// of the nodes
+	withAttributes bool
+}
+
+// WithID is a JSONOption, which includes the stable node ids in the
+// JSON representation of the tree, so that node identity can be
+// tracked across serializations.
+func WithID() JSONOption {
+	opt := func(opts *jsonOptions) {
+		opts.withID = true
+	}
+
+	return opt
+}
+
+// WithMeta is a JSONOption, which includes the metadata of the nodes
+// in the JSON representation of the tree.
+func WithMeta() JSONOption {
+	opt := func(opts *jsonOptions) {
+		opts.withMeta = true
+	}
+
+	return opt
+}
+
+// WithAttributes is a JSONOption, which includes the Dot attributes of
+// the nodes in the JSON representation of the tree.
+func WithAttributes() JSONOption {
+	opt := func(opts *jsonOptions) {
+		opts.withAttributes = true
+	}
+
+	return opt
+}
+
+// jsonNode represents a node from the tree in JSON format.
+type jsonNode[T any] struct {
+	ID         *uint64           `json:"id,omitempty"`
+	Value      T                 `json:"value"`
+	Meta       any               `json:"meta,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Left       *jsonNode[T]      `json:"left"`
+	Right      *jsonNode[T]      `json:"right"`
+}
+
+// toJSONNode converts the tree into its JSON representation.
+func (n *Node[T]) toJSONNode(opts *jsonOptions) *jsonNode[T] {
+	if n == nil {
+		return nil
+	}
+
+	node := &jsonNode[T]{
+		Value: n.Value,
+		Left:  n.Left.toJSONNode(opts),
+		Right: n.Right.toJSONNode(opts),
+	}
+
+	if opts.withID {
+		id := n.id
+		node.ID = &id
+	}
+
+	if opts.withMeta {
+		node.Meta = n.meta
+	}
+
+	if opts.withAttributes && len(n.dotAttributes) > 0 {
+		node.Attributes = make(map[string]string, len(n.dotAttributes))
+		for k, v := range n.dotAttributes {
+			node.Attributes[k] = v
+		}
+	}
+
+	return node
+}
+
+// fromJSONNode populates the node and its sub-tree from the given
+// JSON representation.
+func (n *Node[T]) fromJSONNode(node *jsonNode[T]) {
+	n.Value = node.Value
+	n.meta = node.Meta
+	switch {
+	case node.ID != nil:
+		n.id = *node.ID
+	case n.id == 0:
+		n.id = lastNodeId.Add(1)
+	}
+
+	n.dotAttributes = make(map[string]string, len(node.Attributes))
+	for k, v := range node.Attributes {
+		n.dotAttributes[k] = v
+	}
+
+	// Detach the existing children, so that their parent links and
+	// the cached heights and sizes remain consistent
+	n.attach(&n.Left, nil)
+	n.attach(&n.Right, nil)
+	if node.Left != nil {
+		n.InsertLeft(node.Left.Value).fromJSONNode(node.Left)
+	}
+	if node.Right != nil {
+		n.InsertRight(node.Right.Value).fromJSONNode(node.Right)
+	}
+}
+
+// MarshalJSONWithOptions returns the JSON representation of the tree
+// configured with the given options.
+func (n *Node[T]) MarshalJSONWithOptions(opts ...JSONOption) ([]byte, error) {
+	options := &jsonOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return json.Marshal(n.toJSONNode(options))
+}
+
+// MarshalJSON implements the json.Marshaler interface. The tree is
+// encoded recursively using the value, left and right keys, where
+// missing children are encoded as null. Use MarshalJSONWithOptions in
+// order to include the node ids, metadata or Dot attributes.
+func (n *Node[T]) MarshalJSON() ([]byte, error) {
+	return n.MarshalJSONWithOptions()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It
+// replaces the value and the sub-tree of the node with the ones from
+// the JSON representation. Node ids, metadata and Dot attributes are
+// restored, if present.
+func (n *Node[T]) UnmarshalJSON(data []byte) error {
+	var node *jsonNode[T]
+	if err := json.Unmarshal(data, &node); err != nil {
+		return err
+	}
+
+	if node != nil {
+		n.fromJSONNode(node)
+	}
+
+	return nil
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2022 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//  1. Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer
+//     in this position and unchanged.
+//  2. Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in the
+//     documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR(S) “AS IS” AND ANY EXPRESS OR
+// IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+// OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+// IN NO EVENT SHALL THE AUTHOR(S) BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+// NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+// THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package binarytree_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/dnaeon/go-binarytree.v1"
+)
+
+func TestMarshalJSONWithID(t *testing.T) {
+	// Our test tree
+	//
+	//    1
+	//   / \
+	//  2   3
+	//
+	root := binarytree.NewNode(1)
+	two := root.InsertLeft(2)
+	three := root.InsertRight(3)
+
+	type jsonNode struct {
+		ID    uint64    `json:"id"`
+		Value int       `json:"value"`
+		Left  *jsonNode `json:"left"`
+		Right *jsonNode `json:"right"`
+	}
+
+	decode := func() *jsonNode {
+		data, err := root.MarshalJSONWithOptions(binarytree.WithID())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var result jsonNode
+		if err := json.Unmarshal(data, &result); err != nil {
+			t.Fatal(err)
+		}
+
+		return &result
+	}
+
+	first := decode()
+	second := decode()
+
+	if first.ID != root.ID() || second.ID != root.ID() {
+		t.Fatal("root node id mismatch across serializations")
+	}
+
+	if first.Left.ID != two.ID() || second.Left.ID != two.ID() {
+		t.Fatal("node (2) id mismatch across serializations")
+	}
+
+	if first.Right.ID != three.ID() || second.Right.ID != three.ID() {
+		t.Fatal("node (3) id mismatch across serializations")
+	}
+
+	if root.ID() == two.ID() || two.ID() == three.ID() {
+		t.Fatal("node ids should be unique")
+	}
+
+	// User-supplied ids are used as-is
+	two.SetID(42)
+	if got := decode(); got.Left.ID != 42 {
+		t.Fatalf("want node (2) id 42, got %d", got.Left.ID)
+	}
+}
+
+func TestMarshalJSONWithoutID(t *testing.T) {
+	root := binarytree.NewNode(1)
+	data, err := root.MarshalJSONWithOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"value":1,"left":null,"right":null}`
+	if string(data) != want {
+		t.Fatalf("want %s, got %s", want, string(data))
+	}
+}
+
+func TestMarshalJSONWithMeta(t *testing.T) {
+	root := binarytree.NewNode(1)
+	root.SetMeta(map[string]int{"score": 42})
+
+	data, err := root.MarshalJSONWithOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"value":1,"left":null,"right":null}`
+	if string(data) != want {
+		t.Fatalf("want %s, got %s", want, string(data))
+	}
+
+	data, err = root.MarshalJSONWithOptions(binarytree.WithMeta())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want = `{"value":1,"meta":{"score":42},"left":null,"right":null}`
+	if string(data) != want {
+		t.Fatalf("want %s, got %s", want, string(data))
+	}
+}
+
+func TestMarshalUnmarshalJSON(t *testing.T) {
+	// Our test tree
+	//
+	//     __1
+	//    /   \
+	//   2     3
+	//  / \
+	// 4   5
+	//
+	root := binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root()
+
+	data, err := json.Marshal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"value":1,"left":{"value":2,"left":{"value":4,"left":null,"right":null},"right":{"value":5,"left":null,"right":null}},"right":{"value":3,"left":null,"right":null}}`
+	if string(data) != want {
+		t.Fatalf("want %s, got %s", want, string(data))
+	}
+
+	var got binarytree.Node[int]
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !binarytree.Equal(root, &got) {
+		t.Fatal("round-tripped tree should be equal to the original tree")
+	}
+
+	if got.Left.Parent() != &got || got.Left.Right.Parent() != got.Left {
+		t.Fatal("unmarshaled nodes should have parent links")
+	}
+
+	// Unmarshaling into an existing tree detaches its children
+	existing := binarytree.Build(7).Left(8).Up().Right(9).Root()
+	existing.RecomputeSizes()
+	existing.RecomputeHeights()
+	eight, nine := existing.Left, existing.Right
+	if err := json.Unmarshal([]byte(`{"value":1,"left":{"value":2}}`), existing); err != nil {
+		t.Fatal(err)
+	}
+
+	if eight.Parent() != nil || nine.Parent() != nil {
+		t.Fatal("replaced children should be detached")
+	}
+
+	if existing.Size() != 2 || existing.Height() != 1 {
+		t.Fatalf("want cached size 2 and height 1, got %d and %d", existing.Size(), existing.Height())
+	}
+
+	// Invalid input
+	var invalid binarytree.Node[int]
+	if err := json.Unmarshal([]byte(`{"value":"one"}`), &invalid); err == nil {
+		t.Fatal("expected error for invalid value")
+	}
+}
+
+func TestMarshalJSONWithAttributes(t *testing.T) {
+	// Our test tree
+	//
+	//    1
+	//   / \
+	//  2   3
+	//
+	root := binarytree.NewNode(1)
+	root.InsertLeft(2).AddAttribute("color", "red")
+	root.InsertRight(3)
+
+	data, err := root.MarshalJSONWithOptions(binarytree.WithAttributes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"value":1,"left":{"value":2,"attributes":{"color":"red"},"left":null,"right":null},"right":{"value":3,"left":null,"right":null}}`
+	if string(data) != want {
+		t.Fatalf("want %s, got %s", want, string(data))
+	}
+
+	var got binarytree.Node[int]
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !binarytree.Equal(root, &got) {
+		t.Fatal("round-tripped tree should be equal to the original tree")
+	}
+
+	attrs := got.Left.GetDotAttributes()
+	if attrs != "color=red" {
+		t.Fatalf("want attributes color=red, got %q", attrs)
+	}
+
+	// Attributes are not included by default
+	data, err = json.Marshal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want = `{"value":1,"left":{"value":2,"left":null,"right":null},"right":{"value":3,"left":null,"right":null}}`
+	if string(data) != want {
+		t.Fatalf("want %s, got %s", want, string(data))
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2022 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer
+//    in this position and unchanged.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR(S) ``AS IS'' AND ANY EXPRESS OR
+// IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+// OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+// IN NO EVENT SHALL THE AUTHOR(S) BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+// NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+// THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package binarytree
+
+import "errors"
+
+// errEmptyDeque is returned when popping from an empty deque.
+var errEmptyDeque = errors.New("deque is empty")
+
+// ringDeque is a double-ended queue backed by a growable ring buffer,
+// which is used by the walkers as a stack or a queue. Pushing to and
+// popping from either end is amortized O(1). A ringDeque is not safe
+// for concurrent use, which is fine since each walk owns its deque.
+type ringDeque[T any] struct {
+	// items is the ring buffer holding the items
+	items []T
+
+	// head is the index of the front item
+	head int
+
+	// count is the number of items in the deque
+	count int
+}
+
+// newRingDeque creates a new empty deque.
+func newRingDeque[T any]() *ringDeque[T] {
+	return &ringDeque[T]{}
+}
+
+// grow doubles the capacity of the ring buffer, moving the items to
+// the beginning of the new buffer.
+func (d *ringDeque[T]) grow() {
+	items := make([]T, max(2*len(d.items), 16))
+	n := copy(items, d.items[d.head:])
+	copy(items[n:], d.items[:d.head])
+	d.items = items
+	d.head = 0
+}
+
+// PushBack adds an item to the back of the deque.
+func (d *ringDeque[T]) PushBack(value T) {
+	if d.count == len(d.items) {
+		d.grow()
+	}
+
+	d.items[(d.head+d.count)%len(d.items)] = value
+	d.count++
+}
+
+// PushFront adds an item to the front of the deque.
+func (d *ringDeque[T]) PushFront(value T) {
+	if d.count == len(d.items) {
+		d.grow()
+	}
+
+	d.head = (d.head - 1 + len(d.items)) % len(d.items)
+	d.items[d.head] = value
+	d.count++
+}
+
+// PopFront removes and returns the item from the front of the deque.
+func (d *ringDeque[T]) PopFront() (T, error) {
+	var empty T
+	if d.count == 0 {
+		return empty, errEmptyDeque
+	}
+
+	item := d.items[d.head]
+	d.items[d.head] = empty
+	d.head = (d.head + 1) % len(d.items)
+	d.count--
+
+	return item, nil
+}
+
+// PopBack removes and returns the item from the back of the deque.
+func (d *ringDeque[T]) PopBack() (T, error) {
+	var empty T
+	if d.count == 0 {
+		return empty, errEmptyDeque
+	}
+
+	i := (d.head + d.count - 1) % len(d.items)
+	item := d.items[i]
+	d.items[i] = empty
+	d.count--
+
+	return item, nil
+}
+
+// PeekFront returns the item from the front of the deque without
+// removing it.
+func (d *ringDeque[T]) PeekFront() (T, error) {
+	var empty T
+	if d.count == 0 {
+		return empty, errEmptyDeque
+	}
+
+	return d.items[d.head], nil
+}
+
+// PeekBack returns the item from the back of the deque without
+// removing it.
+func (d *ringDeque[T]) PeekBack() (T, error) {
+	var empty T
+	if d.count == 0 {
+		return empty, errEmptyDeque
+	}
+
+	return d.items[(d.head+d.count-1)%len(d.items)], nil
+}
+
+// IsEmpty returns true, if the deque has no items.
+func (d *ringDeque[T]) IsEmpty() bool {
+	return d.count == 0
+}
+
+// Length returns the number of items in the deque.
+func (d *ringDeque[T]) Length() int {
+	return d.count
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2022 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+// 1. Redistributions of source code must retain the above copyright
+//    notice, this list of conditions and the following disclaimer
+//    in this position and unchanged.
+// 2. Redistributions in binary form must reproduce the above copyright
+//    notice, this list of conditions and the following disclaimer in the
+//    documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR(S) ``AS IS'' AND ANY EXPRESS OR
+// IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+// OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+// IN NO EVENT SHALL THE AUTHOR(S) BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+// NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+// THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package binarytree
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// nilMarker is the token used by Serialize to mark missing children.
+const nilMarker = "#"
+
+// tokenEscaper escapes the encoded values, so that they can contain
+// the separator and the escape character. A value equal to the nil
+// marker is escaped separately, see escapeToken.
+var tokenEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`)
+
+// escapeToken escapes the given encoded value for use as a token.
+func escapeToken(value string) string {
+	if value == nilMarker {
+		return `\` + nilMarker
+	}
+
+	return tokenEscaper.Replace(value)
+}
+
+// splitTokens splits data into tokens at the unescaped commas. The
+// tokens are returned as is, i.e. still escaped.
+func splitTokens(data string) ([]string, error) {
+	tokens := make([]string, 0)
+	start := 0
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			if i == len(data)-1 {
+				return nil, fmt.Errorf("%w: trailing escape character", ErrInvalidSerialization)
+			}
+			i++
+		case ',':
+			tokens = append(tokens, data[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(tokens, data[start:]), nil
+}
+
+// unescapeToken returns the encoded value of the given escaped token.
+func unescapeToken(token string) string {
+	var sb strings.Builder
+	for i := 0; i < len(token); i++ {
+		if token[i] == '\\' {
+			i++
+		}
+		sb.WriteByte(token[i])
+	}
+
+	return sb.String()
+}
+
+// ErrInvalidSerialization is the error which is returned by
+// Deserialize when the input is malformed. The returned error wraps
+// ErrInvalidSerialization with details about the problem.
+var ErrInvalidSerialization = errors.New("invalid serialized tree")
+
+// Serialize encodes the tree rooted at root into a compact string. The
+// values are encoded with encode and joined with commas in
+// level-order, where missing children are marked with "#", e.g. the
+// tree 1(2(4,5),3) is encoded as "1,2,3,4,5". Trailing markers are
+// omitted. Commas and backslashes within the encoded values, as well
+// as values equal to "#", are escaped with a backslash. Serialize
+// returns "#", if root is nil, so that an empty tree is distinguished
+// from a single node holding a value encoded as an empty string.
+func Serialize[T any](root *Node[T], encode func(T) string) string {
+	if root == nil {
+		return nilMarker
+	}
+
+	tokens := []string{escapeToken(encode(root.Value))}
+	queue := newRingDeque[*Node[T]]()
+	queue.PushBack(root)
+
+	for !queue.IsEmpty() {
+		node, err := queue.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		for _, child := range []*Node[T]{node.Left, node.Right} {
+			if child == nil {
+				tokens = append(tokens, nilMarker)
+				continue
+			}
+			tokens = append(tokens, escapeToken(encode(child.Value)))
+			queue.PushBack(child)
+		}
+	}
+
+	last := len(tokens)
+	for last > 0 && tokens[last-1] == nilMarker {
+		last--
+	}
+
+	return strings.Join(tokens[:last], ",")
+}
+
+// Deserialize decodes a tree from its string representation produced
+// by Serialize, using decode to decode the values. It returns nil, if
+// data is "#". An empty data is decoded as a single node holding the
+// value decoded from the empty string. An error wrapping
+// ErrInvalidSerialization is returned, if data is malformed, or if a
+// value cannot be decoded.
+func Deserialize[T any](data string, decode func(string) (T, error)) (*Node[T], error) {
+	tokens, err := splitTokens(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokens[0] == nilMarker {
+		if len(tokens) > 1 {
+			return nil, fmt.Errorf("%w: unexpected values after empty tree", ErrInvalidSerialization)
+		}
+		return nil, nil
+	}
+
+	// newNode creates a node from the token at the given position,
+	// or returns nil if the token is a nil marker.
+	newNode := func(i int) (*Node[T], error) {
+		if tokens[i] == nilMarker {
+			return nil, nil
+		}
+
+		value, err := decode(unescapeToken(tokens[i]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot decode value %q at position %d: %w", ErrInvalidSerialization, tokens[i], i, err)
+		}
+		return NewNode(value), nil
+	}
+
+	root, err := newNode(0)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := newRingDeque[*Node[T]]()
+	queue.PushBack(root)
+	i := 1
+
+	for !queue.IsEmpty() && i < len(tokens) {
+		node, err := queue.PopFront()
+		if err != nil {
+			panic(err)
+		}
+
+		for _, link := range []**Node[T]{&node.Left, &node.Right} {
+			if i >= len(tokens) {
+				break
+			}
+
+			child, err := newNode(i)
+			if err != nil {
+				return nil, err
+			}
+			i++
+
+			if child != nil {
+				child.parent = node
+				*link = child
+				queue.PushBack(child)
+			}
+		}
+	}
+
+	if i < len(tokens) {
+		return nil, fmt.Errorf("%w: unexpected value %q at position %d without a parent node", ErrInvalidSerialization, tokens[i], i)
+	}
+
+	return root, nil
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2022 Marin Atanasov Nikolov <dnaeon@gmail.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//  1. Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer
+//     in this position and unchanged.
+//  2. Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in the
+//     documentation and/or other materials provided with the distribution.
+//
+// THIS SOFTWARE IS PROVIDED BY THE AUTHOR(S) “AS IS” AND ANY EXPRESS OR
+// IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+// OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+// IN NO EVENT SHALL THE AUTHOR(S) BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+// NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+// THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package binarytree_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"gopkg.in/dnaeon/go-binarytree.v1"
+)
+
+func TestSerializeDeserialize(t *testing.T) {
+	tests := []struct {
+		desc string
+		root *binarytree.Node[int]
+		want string
+	}{
+		{
+			desc: "empty tree",
+			root: nil,
+			want: "#",
+		},
+		{
+			desc: "single node",
+			root: binarytree.NewNode(1),
+			want: "1",
+		},
+		{
+			desc: "complete tree",
+			root: binarytree.Build(1).Left(2).Left(4).Up().Right(5).Up().Up().Right(3).Root(),
+			want: "1,2,3,4,5",
+		},
+		{
+			desc: "tree with gaps",
+			root: binarytree.Build(1).Left(2).Right(4).Up().Up().Right(3).Right(5).Root(),
+			want: "1,2,3,#,4,#,5",
+		},
+		{
+			desc: "degenerate tree",
+			root: binarytree.Build(1).Left(2).Left(3).Root(),
+			want: "1,2,#,3",
+		},
+	}
+
+	for _, test := range tests {
+		got := binarytree.Serialize(test.root, strconv.Itoa)
+		if got != test.want {
+			t.Fatalf("%s: want %q, got %q", test.desc, test.want, got)
+		}
+
+		root, err := binarytree.Deserialize(got, strconv.Atoi)
+		if err != nil {
+			t.Fatalf("%s: %s", test.desc, err)
+		}
+
+		if !binarytree.Equal(root, test.root) {
+			t.Fatalf("%s: deserialized tree should be equal to the original tree", test.desc)
+		}
+	}
+
+	// Trailing markers are accepted as well
+	root, err := binarytree.Deserialize("1,2,#,#,#", strconv.Atoi)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !binarytree.Equal(root, binarytree.Build(1).Left(2).Root()) {
+		t.Fatal("unexpected tree deserialized with trailing markers")
+	}
+
+	if root.Left.Parent() != root {
+		t.Fatal("deserialized nodes should have parent links")
+	}
+}
+
+func TestSerializeEscaping(t *testing.T) {
+	// Our test tree
+	//
+	//        a,b
+	//       /   \
+	//      #     c\
+	//     /
+	//  ,#,
+	//
+	root := binarytree.Build("a,b").Left("#").Left(",#,").Up().Up().Right(`c\`).Root()
+	identity := func(s string) string { return s }
+	decode := func(s string) (string, error) { return s, nil }
+
+	data := binarytree.Serialize(root, identity)
+	want := `a\,b,\#,c\\,\,#\,`
+	if data != want {
+		t.Fatalf("want %q, got %q", want, data)
+	}
+
+	got, err := binarytree.Deserialize(data, decode)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !binarytree.Equal(got, root) {
+		t.Fatal("deserialized tree should be equal to the original tree")
+	}
+}
+
+func TestSerializeEmptyValue(t *testing.T) {
+	identity := func(s string) string { return s }
+	decode := func(s string) (string, error) { return s, nil }
+
+	// A single node holding an empty value is not an empty tree
+	root := binarytree.NewNode("")
+	data := binarytree.Serialize(root, identity)
+	got, err := binarytree.Deserialize(data, decode)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got == nil || !binarytree.Equal(got, root) {
+		t.Fatal("deserialized tree should be equal to the original tree")
+	}
+
+	// The empty tree round-trips as well
+	data = binarytree.Serialize[string](nil, identity)
+	if got, err := binarytree.Deserialize(data, decode); err != nil || got != nil {
+		t.Fatalf("want empty tree, got %v, %v", got, err)
+	}
+}
+
+func TestDeserializeInvalid(t *testing.T) {
+	tests := []string{
+		"1,two,3",
+		"#,1",
+		"1,#,#,4",
+		"1,,2",
+		`1,2\`,
+		"",
+	}
+
+	for _, data := range tests {
+		_, err := binarytree.Deserialize(data, strconv.Atoi)
+		if !errors.Is(err, binarytree.ErrInvalidSerialization) {
+			t.Fatalf("want invalid serialization error for %q, got %v", data, err)
+		}
+	}
+}